@@ -0,0 +1,182 @@
+package sling
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyStats are the latency percentiles of a route, see AdaptiveTimeout.
+type LatencyStats struct {
+	// Count is the number of latencies the percentiles are computed from.
+	Count int
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	// Timeout is the suggested timeout of the route, see AdaptiveTimeout.
+	Timeout time.Duration
+}
+
+// AdaptiveTimeout is an experimental tracker of the latency percentiles of
+// routes (method and URL path), suggesting a timeout for each route of
+// Multiplier times its Percentile latency, within Min and Max, and applying
+// it to requests if Apply is set. The percentiles are passed to OnStats,
+// e.g. to export them as metrics for capacity planning:
+//
+//	adaptive := &sling.AdaptiveTimeout{Min: 100 * time.Millisecond, Max: 10 * time.Second, Apply: true}
+//	s.AdaptiveTimeout(adaptive)
+//
+// An AdaptiveTimeout may be shared by several Slings and must not be copied
+// after first use.
+type AdaptiveTimeout struct {
+	// Percentile of the latencies the timeout derives from, 0.99 if 0.
+	Percentile float64
+	// Multiplier of the Percentile latency, 2 if 0.
+	Multiplier float64
+	// Min and Max bound the suggested timeout, if not 0.
+	Min, Max time.Duration
+	// Apply applies the suggested timeout to requests, when at least
+	// MinSamples latencies of their route were observed.
+	Apply bool
+	// MinSamples is the number of latencies needed to apply a timeout, 20
+	// if 0.
+	MinSamples int
+	// Window is the number of latest latencies kept per route, 200 if 0.
+	Window int
+	// OnStats, if not nil, is called with the stats of a route whenever a
+	// latency of the route is observed.
+	OnStats func(route string, stats LatencyStats)
+
+	mu     sync.Mutex
+	routes map[string]*latencyWindow
+}
+
+// latencyWindow is a ring buffer of the latest latencies of a route.
+type latencyWindow struct {
+	latencies []time.Duration
+	next      int
+}
+
+// AdaptiveTimeout tracks the latencies of requests with a, applying its
+// suggested timeouts if a.Apply is set. A nil a disables tracking.
+func (s *Sling) AdaptiveTimeout(a *AdaptiveTimeout) *Sling {
+	s.checkMutable()
+	s.adaptiveTimeout = a
+	return s
+}
+
+// Stats returns the latency stats of route, e.g. "GET /users", false if no
+// latency of route was observed.
+func (a *AdaptiveTimeout) Stats(route string) (LatencyStats, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	w, ok := a.routes[route]
+	if !ok {
+		return LatencyStats{}, false
+	}
+	return a.stats(w), true
+}
+
+// route returns the route of req.
+func route(req *http.Request) string {
+	return req.Method + " " + req.URL.Path
+}
+
+// withTimeout returns req bounded by the suggested timeout of its route, if
+// applied, and the function releasing it.
+func (a *AdaptiveTimeout) withTimeout(req *http.Request) (*http.Request, context.CancelFunc) {
+	if !a.Apply {
+		return req, func() {}
+	}
+	stats, ok := a.Stats(route(req))
+	if !ok || stats.Count < a.minSamples() {
+		return req, func() {}
+	}
+	ctx, cancel := withTimeoutReason(req.Context(), stats.Timeout, CancelAdaptiveTimeout)
+	return req.WithContext(ctx), cancel
+}
+
+// observe records the latency of a response to req.
+func (a *AdaptiveTimeout) observe(req *http.Request, latency time.Duration) {
+	key := route(req)
+	a.mu.Lock()
+	if a.routes == nil {
+		a.routes = make(map[string]*latencyWindow)
+	}
+	w, ok := a.routes[key]
+	if !ok {
+		w = &latencyWindow{}
+		a.routes[key] = w
+	}
+	if size := a.window(); len(w.latencies) < size {
+		w.latencies = append(w.latencies, latency)
+	} else {
+		w.latencies[w.next%size] = latency
+		w.next++
+	}
+	var stats LatencyStats
+	if a.OnStats != nil {
+		stats = a.stats(w)
+	}
+	a.mu.Unlock()
+	if a.OnStats != nil {
+		a.OnStats(key, stats)
+	}
+}
+
+// stats computes the stats of w. a.mu must be held.
+func (a *AdaptiveTimeout) stats(w *latencyWindow) LatencyStats {
+	sorted := append([]time.Duration(nil), w.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	stats := LatencyStats{
+		Count: len(sorted),
+		P50:   percentile(sorted, 0.5),
+		P90:   percentile(sorted, 0.9),
+		P99:   percentile(sorted, 0.99),
+	}
+	multiplier := a.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	p := a.Percentile
+	if p <= 0 || p > 1 {
+		p = 0.99
+	}
+	stats.Timeout = time.Duration(float64(percentile(sorted, p)) * multiplier)
+	if a.Min > 0 && stats.Timeout < a.Min {
+		stats.Timeout = a.Min
+	}
+	if a.Max > 0 && stats.Timeout > a.Max {
+		stats.Timeout = a.Max
+	}
+	return stats
+}
+
+// percentile returns the p percentile of the sorted latencies, with the
+// nearest rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(p*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+func (a *AdaptiveTimeout) minSamples() int {
+	if a.MinSamples <= 0 {
+		return 20
+	}
+	return a.MinSamples
+}
+
+func (a *AdaptiveTimeout) window() int {
+	if a.Window <= 0 {
+		return 200
+	}
+	return a.Window
+}
@@ -0,0 +1,130 @@
+package sling
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RotateAddresses makes the Sling's connections to hosts resolving to
+// several A/AAAA records fail over to the next address immediately when a
+// connection cannot be established, before any retry backoff applies, so a
+// single bad backend IP doesn't fail requests:
+//
+//	s.RotateAddresses(2 * time.Second)
+//
+// Each address is dialed with its own dialTimeout (10 seconds if <= 0).
+// Connections start from the next address in turn, and addresses which
+// failed are tried last for 30 seconds. The rotating dialer wraps the dial
+// function of the Sling's copy of the transport (see Doer), so it composes
+// with a custom DialContext.
+func (s *Sling) RotateAddresses(dialTimeout time.Duration) *Sling {
+	s.checkMutable()
+	if dialTimeout <= 0 {
+		dialTimeout = 10 * time.Second
+	}
+	s.configureTransport("RotateAddresses", dialTimeout.String(), func(t *http.Transport) {
+		dial := t.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{KeepAlive: 30 * time.Second}).DialContext
+		}
+		r := &addressRotator{
+			dial:        dial,
+			lookup:      net.DefaultResolver.LookupIPAddr,
+			dialTimeout: dialTimeout,
+			cooldown:    30 * time.Second,
+			now:         time.Now,
+		}
+		t.DialContext = r.DialContext
+	})
+	return s
+}
+
+// addressRotator dials the addresses of a host in turn, see RotateAddresses.
+type addressRotator struct {
+	dial        func(ctx context.Context, network, addr string) (net.Conn, error)
+	lookup      func(ctx context.Context, host string) ([]net.IPAddr, error)
+	dialTimeout time.Duration
+	// cooldown is how long failed addresses are tried last
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu sync.Mutex
+	// next is the index of the first address to dial per host
+	next map[string]int
+	// failed holds when addresses last failed
+	failed map[string]time.Time
+}
+
+// DialContext dials addr, trying each address of its host in turn until a
+// connection is established.
+func (r *addressRotator) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return r.dial(ctx, network, addr)
+	}
+	ips, err := r.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, ip := range r.order(host, ips) {
+		target := net.JoinHostPort(ip.String(), port)
+		dialCtx, cancel := context.WithTimeout(ctx, r.dialTimeout)
+		conn, err := r.dial(dialCtx, network, target)
+		cancel()
+		if err == nil {
+			return conn, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		r.markFailed(target)
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil, &net.DNSError{Err: "no addresses", Name: host, IsNotFound: true}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// order returns the addresses of host starting from the next one in turn,
+// with recently failed addresses last.
+func (r *addressRotator) order(host string, ips []net.IPAddr) []net.IPAddr {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.next == nil {
+		r.next = make(map[string]int)
+	}
+	start := r.next[host] % max(len(ips), 1)
+	r.next[host] = start + 1
+	now := r.now()
+	healthy := make([]net.IPAddr, 0, len(ips))
+	var failed []net.IPAddr
+	for i := range ips {
+		ip := ips[(start+i)%len(ips)]
+		if at, ok := r.failed[ip.String()]; ok && now.Sub(at) < r.cooldown {
+			failed = append(failed, ip)
+			continue
+		}
+		healthy = append(healthy, ip)
+	}
+	return append(healthy, failed...)
+}
+
+// markFailed records that target could not be dialed.
+func (r *addressRotator) markFailed(target string) {
+	host, _, _ := net.SplitHostPort(target)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failed == nil {
+		r.failed = make(map[string]time.Time)
+	}
+	r.failed[host] = r.now()
+}
@@ -0,0 +1,77 @@
+package sling
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ArrayEncoding is the encoding of multi-valued query parameters, see
+// ArrayEncoding.
+type ArrayEncoding int
+
+const (
+	// ArrayRepeat repeats the key of each value, e.g. "a=1&a=2" (default).
+	ArrayRepeat ArrayEncoding = iota
+	// ArrayComma joins the values with commas, e.g. "a=1,2", commas within
+	// values being escaped.
+	ArrayComma
+	// ArrayBrackets repeats the key suffixed with brackets, e.g.
+	// "a[]=1&a[]=2", as expected by PHP and Rails.
+	ArrayBrackets
+)
+
+// ArrayEncoding sets the encoding of query parameters with several values,
+// e.g. slice fields of query structs (see QueryStruct) or QueryValues, since
+// APIs expect different styles. Keys already ending with brackets are left
+// as is.
+func (s *Sling) ArrayEncoding(encoding ArrayEncoding) *Sling {
+	s.checkMutable()
+	s.arrayEncoding = encoding
+	return s
+}
+
+// encodeQuery encodes values like url.Values.Encode, multi-valued keys
+// being encoded with encoding.
+func encodeQuery(values url.Values, encoding ArrayEncoding) string {
+	if encoding == ArrayRepeat {
+		return values.Encode()
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, key := range keys {
+		vs := values[key]
+		if len(vs) < 2 || strings.HasSuffix(key, "[]") {
+			for _, v := range vs {
+				writeQueryPair(&b, url.QueryEscape(key), url.QueryEscape(v))
+			}
+			continue
+		}
+		switch encoding {
+		case ArrayComma:
+			escaped := make([]string, len(vs))
+			for i, v := range vs {
+				escaped[i] = url.QueryEscape(v)
+			}
+			writeQueryPair(&b, url.QueryEscape(key), strings.Join(escaped, ","))
+		case ArrayBrackets:
+			for _, v := range vs {
+				writeQueryPair(&b, url.QueryEscape(key+"[]"), url.QueryEscape(v))
+			}
+		}
+	}
+	return b.String()
+}
+
+func writeQueryPair(b *strings.Builder, key, value string) {
+	if b.Len() > 0 {
+		b.WriteByte('&')
+	}
+	b.WriteString(key)
+	b.WriteByte('=')
+	b.WriteString(value)
+}
@@ -0,0 +1,129 @@
+package sling
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+const (
+	batchContentType = "multipart/mixed"
+	httpPartType     = "application/http"
+)
+
+// BodyBatch sets the Sling's body to a multipart/mixed batch of reqs, each
+// part embedding one request (application/http), as accepted by Google and
+// OData batch endpoints. Parts are numbered from 1 with their Content-ID
+// header. Use BatchResponses to split the batch response:
+//
+//	get, _ := api.New().Get("users/1").Request()
+//	del, _ := api.New().Delete("users/2").Request()
+//	resp, err := api.New().Post("batch").BodyBatch(get, del).ReceiveSuccess(nil)
+//	parts, err := sling.BatchResponses(resp)
+//
+// Request bodies are read when the batch body is built, through GetBody when
+// available so that the batch can be sent again. Nil requests are ignored.
+func (s *Sling) BodyBatch(reqs ...*http.Request) *Sling {
+	var parts []*http.Request
+	for _, req := range reqs {
+		if req != nil {
+			parts = append(parts, req)
+		}
+	}
+	if len(parts) == 0 {
+		return s
+	}
+	return s.BodyProvider(batchBodyProvider{reqs: parts, boundary: randomBoundary()})
+}
+
+// batchBodyProvider encodes requests as a multipart/mixed batch.
+type batchBodyProvider struct {
+	reqs     []*http.Request
+	boundary string
+}
+
+func (p batchBodyProvider) ContentType() string {
+	return batchContentType + "; boundary=" + p.boundary
+}
+
+func (p batchBodyProvider) Body() (io.Reader, error) {
+	buf := &bytes.Buffer{}
+	mw := multipart.NewWriter(buf)
+	if err := mw.SetBoundary(p.boundary); err != nil {
+		return nil, err
+	}
+	for i, req := range p.reqs {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", httpPartType)
+		header.Set("Content-ID", "<"+strconv.Itoa(i+1)+">")
+		part, err := mw.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBatchRequest(part, req); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// writeBatchRequest writes req in HTTP/1.1 wire format, reading its body
+// from GetBody when available.
+func writeBatchRequest(w io.Writer, req *http.Request) error {
+	req = req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		req.Body = body
+	}
+	return req.Write(w)
+}
+
+// BatchResponses splits a multipart/mixed batch response (see BodyBatch)
+// into the responses of its parts, in order. Their bodies are buffered into
+// RawData.
+func BatchResponses(resp *Response) ([]*Response, error) {
+	if resp == nil || resp.Response == nil {
+		return nil, errors.New("sling: nil batch response")
+	}
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		return nil, fmt.Errorf("sling: not a multipart batch response: %q", resp.Header.Get("Content-Type"))
+	}
+	body := resp.Reader()
+	defer resp.ResetBody()
+	mr := multipart.NewReader(body, params["boundary"])
+	var responses []*Response
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return responses, nil
+		}
+		if err != nil {
+			return responses, err
+		}
+		partResp, err := http.ReadResponse(bufio.NewReader(part), resp.Request)
+		if err != nil {
+			return responses, fmt.Errorf("sling: batch part %d: %w", len(responses)+1, err)
+		}
+		rawData, err := io.ReadAll(partResp.Body)
+		partResp.Body.Close()
+		if err != nil {
+			return responses, fmt.Errorf("sling: batch part %d: %w", len(responses)+1, err)
+		}
+		responses = append(responses, NewResponse(partResp, rawData))
+	}
+}
@@ -0,0 +1,243 @@
+package sling
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// BatchState records the outcome of the requests of a batch job, keyed by
+// user supplied IDs, so that a restarted job skips the requests which
+// already succeeded, see ResumeBatch.
+type BatchState interface {
+	// Completed reports whether the request id succeeded.
+	Completed(id string) bool
+	// Record records the outcome of the request id, a nil err for success.
+	Record(id string, err error) error
+}
+
+// FileBatchState is a BatchState persisted to a log file, appending a JSON
+// line per outcome so that recording is independent of the size of the
+// batch. Close compacts the log to the last outcome of each request.
+type FileBatchState struct {
+	path string
+
+	mu       sync.Mutex
+	file     *os.File
+	lines    int
+	outcomes map[string]batchOutcome
+}
+
+// batchOutcome is the stored outcome of a request.
+type batchOutcome struct {
+	ID    string `json:"id"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// NewFileBatchState returns a FileBatchState stored in path, restoring the
+// outcomes recorded by a previous process. A last line cut short by a crash
+// is dropped.
+func NewFileBatchState(path string) (*FileBatchState, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	state := &FileBatchState{path: path, file: file, outcomes: make(map[string]batchOutcome)}
+	if err := state.load(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return state, nil
+}
+
+// load replays the log into the outcomes and positions the file at the end
+// of its last complete line.
+func (f *FileBatchState) load() error {
+	b, err := io.ReadAll(f.file)
+	if err != nil {
+		return err
+	}
+	end := bytes.LastIndexByte(b, '\n') + 1
+	for i, line := range bytes.Split(b[:end], []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var outcome batchOutcome
+		if err := json.Unmarshal(line, &outcome); err != nil {
+			return fmt.Errorf("sling: batch state %s line %d: %w", f.path, i+1, err)
+		}
+		f.outcomes[outcome.ID] = outcome
+		f.lines++
+	}
+	if end < len(b) {
+		if err := f.file.Truncate(int64(end)); err != nil {
+			return err
+		}
+	}
+	_, err = f.file.Seek(int64(end), io.SeekStart)
+	return err
+}
+
+// Completed reports whether the request id succeeded.
+func (f *FileBatchState) Completed(id string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.outcomes[id].OK
+}
+
+// Failure returns the recorded error of the request id, "" if it succeeded
+// or wasn't sent.
+func (f *FileBatchState) Failure(id string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.outcomes[id].Error
+}
+
+// Record records the outcome of the request id, appending it to the log.
+func (f *FileBatchState) Record(id string, err error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return fmt.Errorf("sling: batch state %s is closed", f.path)
+	}
+	outcome := batchOutcome{ID: id, OK: err == nil}
+	if err != nil {
+		outcome.Error = err.Error()
+	}
+	line, err := json.Marshal(outcome)
+	if err != nil {
+		return err
+	}
+	if _, err := f.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	f.outcomes[id] = outcome
+	f.lines++
+	return nil
+}
+
+// Close compacts the log, when requests were recorded more than once, and
+// closes it.
+func (f *FileBatchState) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	if err != nil || f.lines == len(f.outcomes) {
+		return err
+	}
+	return f.compact()
+}
+
+// compact replaces the log with the last outcome of each request.
+func (f *FileBatchState) compact() error {
+	ids := make([]string, 0, len(f.outcomes))
+	for id := range f.outcomes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var b bytes.Buffer
+	for _, id := range ids {
+		line, err := json.Marshal(f.outcomes[id])
+		if err != nil {
+			return err
+		}
+		b.Write(append(line, '\n'))
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(b.Bytes())
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), f.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	f.lines = len(ids)
+	return nil
+}
+
+// BatchRequest is a request of a batch job identified by ID, see
+// ResumeBatch.
+type BatchRequest struct {
+	ID      string
+	Request *http.Request
+}
+
+// ResumeBatch sends the requests which state doesn't record as completed in
+// one multipart/mixed batch (see BodyBatch) with s, which sets the method and
+// URL of the batch endpoint, and records the outcome of each of them in
+// state. Parts with a success response (see WithSuccessDecider) succeed,
+// others fail with a *StatusError and are sent again by the next
+// ResumeBatch, as are all requests if the batch itself fails:
+//
+//	state, err := sling.NewFileBatchState("/var/lib/job/batch.log")
+//	defer state.Close()
+//	parts, err := sling.ResumeBatch(api.New().Post("batch"), state, reqs...)
+//
+// The responses of the sent requests are returned by ID.
+func ResumeBatch(s *Sling, state BatchState, reqs ...BatchRequest) (map[string]*Response, error) {
+	var pending []BatchRequest
+	var httpReqs []*http.Request
+	for _, req := range reqs {
+		if req.Request != nil && !state.Completed(req.ID) {
+			pending = append(pending, req)
+			httpReqs = append(httpReqs, req.Request)
+		}
+	}
+	responses := make(map[string]*Response, len(pending))
+	if len(pending) == 0 {
+		return responses, nil
+	}
+	fail := func(err error) (map[string]*Response, error) {
+		for _, req := range pending {
+			if recordErr := state.Record(req.ID, err); recordErr != nil {
+				return responses, recordErr
+			}
+		}
+		return responses, err
+	}
+	batch := s.New().BodyBatch(httpReqs...)
+	resp, err := batch.Receive(nil, nil)
+	if err != nil {
+		return fail(err)
+	}
+	if !batch.isSuccess(resp.Response) {
+		return fail(newStatusError(resp))
+	}
+	parts, err := BatchResponses(resp)
+	if err == nil && len(parts) != len(pending) {
+		err = fmt.Errorf("sling: batch response has %d parts for %d requests", len(parts), len(pending))
+	}
+	if err != nil {
+		return fail(err)
+	}
+	for i, part := range parts {
+		id := pending[i].ID
+		responses[id] = part
+		var partErr error
+		if !batch.isSuccess(part.Response) {
+			partErr = newStatusError(part)
+		}
+		if err := state.Record(id, partErr); err != nil {
+			return responses, err
+		}
+	}
+	return responses, nil
+}
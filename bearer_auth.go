@@ -0,0 +1,92 @@
+package sling
+
+import (
+	"context"
+	"net/http"
+)
+
+// TokenFunc returns the bearer token of a request, see SetBearerAuthFunc.
+type TokenFunc func(ctx context.Context) (string, error)
+
+type tokenRefreshKey struct{}
+
+// bearerTokenKey is the context key of a token resolved before the request
+// is sent, e.g. for the key of memoized values (see Memoize).
+type bearerTokenKey struct{}
+
+// SetBearerAuthFunc resolves the bearer token of each request with token
+// when it is sent, overriding any Authorization header, so tokens can be
+// rotated without rebuilding Slings:
+//
+//	s.SetBearerAuthFunc(func(ctx context.Context) (string, error) {
+//		return tokens.Current(ctx)
+//	})
+//
+// When a request is answered 401 Unauthorized, token is called once more,
+// with a context for which IsTokenRefresh is true, and the request is
+// retried with the new token. Requests with bodies that cannot be rewound
+// are not retried. The token is set before Middlewares run, so signing
+// Middlewares cover it. A nil token disables it.
+func (s *Sling) SetBearerAuthFunc(token TokenFunc) *Sling {
+	s.checkMutable()
+	s.bearerToken = token
+	return s
+}
+
+// IsTokenRefresh reports whether a TokenFunc is called to replace a token
+// rejected with 401 Unauthorized, e.g. to bypass a token cache.
+func IsTokenRefresh(ctx context.Context) bool {
+	refresh, _ := ctx.Value(tokenRefreshKey{}).(bool)
+	return refresh
+}
+
+// bearerDoer sets the bearer token of requests, refreshing it once on 401
+// Unauthorized responses.
+type bearerDoer struct {
+	next  Doer
+	token TokenFunc
+}
+
+func (d *bearerDoer) Do(req *http.Request) (*http.Response, []byte, error) {
+	authorized, err := d.authorize(req.Context(), req)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, rawData, err := d.next.Do(authorized)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, rawData, err
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, rawData, err
+	}
+	retry := req
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, rawData, nil
+		}
+		retry = req.Clone(req.Context())
+		retry.Body = body
+	}
+	authorized, err = d.authorize(context.WithValue(req.Context(), tokenRefreshKey{}, true), retry)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp.Body.Close()
+	return d.next.Do(authorized)
+}
+
+// authorize returns a copy of req with the token resolved with ctx, or
+// already resolved for the request unless it is refreshed.
+func (d *bearerDoer) authorize(ctx context.Context, req *http.Request) (*http.Request, error) {
+	token, ok := ctx.Value(bearerTokenKey{}).(string)
+	if !ok || IsTokenRefresh(ctx) {
+		var err error
+		if token, err = d.token(ctx); err != nil {
+			return nil, err
+		}
+	}
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set(hdrAuthorizationKey, "Bearer "+token)
+	return authorized, nil
+}
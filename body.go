@@ -2,11 +2,19 @@ package sling
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 
-	goquery "github.com/google/go-querystring/query"
+	"github.com/fxamacker/cbor/v2"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
 )
 
 // BodyProvider provides Body content for http.Request attachment.
@@ -49,10 +57,124 @@ func (p jsonBodyProvider) Body() (io.Reader, error) {
 	return buf, nil
 }
 
+// cborBodyProvider encodes a cbor (or json) tagged struct value as CBOR Body
+// for requests. See https://pkg.go.dev/github.com/fxamacker/cbor/v2 for
+// details.
+type cborBodyProvider struct {
+	payload interface{}
+}
+
+func (p cborBodyProvider) ContentType() string {
+	return cborContentType
+}
+
+func (p cborBodyProvider) Body() (io.Reader, error) {
+	b, err := cbor.Marshal(p.payload)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
+
+// yamlBodyProvider encodes a yaml tagged struct value as YAML Body for
+// requests. See https://pkg.go.dev/gopkg.in/yaml.v3#Marshal for details.
+type yamlBodyProvider struct {
+	payload interface{}
+}
+
+func (p yamlBodyProvider) ContentType() string {
+	return yamlContentType
+}
+
+func (p yamlBodyProvider) Body() (io.Reader, error) {
+	b, err := yaml.Marshal(p.payload)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
+
+// bytesBodyProvider provides a raw payload as Body for requests.
+type bytesBodyProvider struct {
+	payload     []byte
+	contentType string
+}
+
+func (p bytesBodyProvider) ContentType() string {
+	return p.contentType
+}
+
+func (p bytesBodyProvider) Body() (io.Reader, error) {
+	return bytes.NewReader(p.payload), nil
+}
+
+// streamBodyProvider streams the reader returned by open as Body for
+// requests, opening a new one for each request and replay.
+type streamBodyProvider struct {
+	open        func() (io.ReadCloser, error)
+	contentType string
+}
+
+func (p streamBodyProvider) ContentType() string {
+	return p.contentType
+}
+
+func (p streamBodyProvider) Body() (io.Reader, error) {
+	body, err := p.open()
+	if err != nil {
+		return nil, err
+	}
+	return &rewindableBody{ReadCloser: body, getBody: p.open}, nil
+}
+
+// funcBodyProvider provides the reader returned by fn as Body for requests,
+// calling fn for each request and replay.
+type funcBodyProvider struct {
+	fn func(ctx context.Context) (io.Reader, string, error)
+}
+
+func (p funcBodyProvider) ContentType() string {
+	return ""
+}
+
+func (p funcBodyProvider) Body() (io.Reader, error) {
+	body, _, err := p.bodyContext(context.Background())
+	return body, err
+}
+
+// bodyContext returns the body and Content-Type returned by fn for ctx.
+func (p funcBodyProvider) bodyContext(ctx context.Context) (io.Reader, string, error) {
+	body, contentType, err := p.fn(ctx)
+	if err != nil || body == nil {
+		return nil, contentType, err
+	}
+	getBody := func() (io.ReadCloser, error) {
+		body, _, err := p.fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if body == nil {
+			return http.NoBody, nil
+		}
+		return readCloser(body), nil
+	}
+	return &rewindableBody{ReadCloser: readCloser(body), getBody: getBody}, contentType, nil
+}
+
+// readCloser returns r as an io.ReadCloser, closing it if it is one.
+func readCloser(r io.Reader) io.ReadCloser {
+	if rc, ok := r.(io.ReadCloser); ok {
+		return rc
+	}
+	return io.NopCloser(r)
+}
+
 // formBodyProvider encodes a url tagged struct value as Body for requests.
 // See https://godoc.org/github.com/google/go-querystring/query for details.
 type formBodyProvider struct {
 	payload interface{}
+	// rendering of time values, see TimeFormat
+	timeFormat *timeFormat
 }
 
 func (p formBodyProvider) ContentType() string {
@@ -60,9 +182,76 @@ func (p formBodyProvider) ContentType() string {
 }
 
 func (p formBodyProvider) Body() (io.Reader, error) {
-	values, err := goquery.Values(p.payload)
+	values, err := encodeValues(p.payload, p.timeFormat)
 	if err != nil {
 		return nil, err
 	}
 	return strings.NewReader(values.Encode()), nil
 }
+
+// formValuesBodyProvider encodes url.Values as Body for requests.
+type formValuesBodyProvider struct {
+	values url.Values
+}
+
+func (p formValuesBodyProvider) ContentType() string {
+	return formContentType
+}
+
+func (p formValuesBodyProvider) Body() (io.Reader, error) {
+	return strings.NewReader(p.values.Encode()), nil
+}
+
+// fileBodyProvider streams the file at path as Body for requests.
+type fileBodyProvider struct {
+	path string
+}
+
+func (p fileBodyProvider) ContentType() string {
+	if contentType := mime.TypeByExtension(filepath.Ext(p.path)); contentType != "" {
+		return contentType
+	}
+	return octetStreamType
+}
+
+func (p fileBodyProvider) Body() (io.Reader, error) {
+	f, err := os.Open(p.path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &fileBody{File: f, size: info.Size()}, nil
+}
+
+// fileBody is a file request body whose size is known and which can be
+// reopened for retries and redirects (see http.Request.GetBody).
+type fileBody struct {
+	*os.File
+	size int64
+}
+
+func (b *fileBody) getBody() (io.ReadCloser, error) {
+	return os.Open(b.Name())
+}
+
+// protoBodyProvider encodes a proto.Message as binary protobuf Body for
+// requests.
+type protoBodyProvider struct {
+	payload proto.Message
+}
+
+func (p protoBodyProvider) ContentType() string {
+	return protoContentType
+}
+
+func (p protoBodyProvider) Body() (io.Reader, error) {
+	b, err := proto.Marshal(p.payload)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
@@ -0,0 +1,133 @@
+package sling
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// BodyLogger logs the requests of a Sling with their responses, and the
+// bodies of a sample of them, so verbose diagnostics can run in production
+// without drowning the log pipeline:
+//
+//	bodies := &sling.BodyLogger{Logger: logger, SampleRate: 0.01, Redactor: sling.NewRedactor("$.password")}
+//	api := sling.New().Base("https://api.example.com/").Use(bodies.Middleware())
+//
+// Every request is logged with its method, URL, status and duration.
+// Bodies are logged for SampleRate of the requests, and always for failed
+// requests: transport errors and 4xx or 5xx responses. Response bodies are
+// only logged when buffered (not for SpillToDisk or NDJSON).
+type BodyLogger struct {
+	// Logger logs the requests, a discarding logger if nil.
+	Logger Logger
+	// SampleRate is the fraction of successful requests logged with their
+	// bodies, from 0 (none) to 1 (all).
+	SampleRate float64
+	// MaxBodyBytes truncates logged bodies, 4 KiB if 0.
+	MaxBodyBytes int
+	// Redactor masks the values at JSON paths of logged bodies, e.g.
+	// credentials or personal data.
+	Redactor *Redactor
+
+	// sample returns a number in [0, 1), rand.Float64 if nil.
+	sample func() float64
+}
+
+// Middleware returns a Middleware logging requests with l (see Use).
+func (l *BodyLogger) Middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, []byte, error) {
+			sampled := l.sampled()
+			var reqBody []byte
+			if sampled {
+				req = req.Clone(req.Context())
+				var err error
+				if reqBody, err = bufferBody(req); err != nil {
+					return nil, nil, err
+				}
+			}
+			start := time.Now()
+			resp, rawData, err := next.Do(req)
+			fields := Fields{
+				"method":   req.Method,
+				"url":      req.URL.Redacted(),
+				"duration": time.Since(start),
+			}
+			failed := err != nil || resp.StatusCode >= 400
+			if sampled || failed {
+				if !sampled {
+					reqBody = replayedBody(req)
+				}
+				if reqBody != nil {
+					fields["request_body"] = l.body(reqBody)
+				}
+				if rawData != nil {
+					fields["response_body"] = l.body(rawData)
+				}
+			}
+			logger := l.logger().WithContext(req.Context())
+			switch {
+			case err != nil:
+				fields["error"] = err.Error()
+				logger.WithFields(fields).Error("request failed")
+			case failed:
+				fields["status"] = resp.StatusCode
+				logger.WithFields(fields).Error("request failed")
+			default:
+				fields["status"] = resp.StatusCode
+				logger.WithFields(fields).Info("request")
+			}
+			return resp, rawData, err
+		})
+	}
+}
+
+func (l *BodyLogger) sampled() bool {
+	if l.SampleRate <= 0 {
+		return false
+	}
+	sample := l.sample
+	if sample == nil {
+		sample = rand.Float64
+	}
+	return sample() < l.SampleRate
+}
+
+func (l *BodyLogger) logger() Logger {
+	if l.Logger == nil {
+		return NewDefaultLogger()
+	}
+	return l.Logger
+}
+
+// body returns the redacted and truncated rendering of a logged body.
+func (l *BodyLogger) body(payload []byte) string {
+	payload = l.Redactor.Redact(payload)
+	limit := l.MaxBodyBytes
+	if limit <= 0 {
+		limit = 4 << 10
+	}
+	if len(payload) > limit {
+		return string(payload[:limit]) + "...(truncated)"
+	}
+	return string(payload)
+}
+
+// replayedBody returns a copy of the body of a sent request which can be
+// rewound, nil otherwise.
+func replayedBody(req *http.Request) []byte {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		return nil
+	}
+	return payload
+}
@@ -0,0 +1,48 @@
+package sling
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// BuilderError describes a misconfiguration of a Sling, e.g. an invalid path,
+// returned by Request (and the Receive methods), see Err.
+type BuilderError struct {
+	// Method is the builder method, e.g. "Path".
+	Method string
+	// Arg is the invalid argument.
+	Arg string
+	Err error
+}
+
+func (e *BuilderError) Error() string {
+	return fmt.Sprintf("sling: %s(%q): %v", e.Method, e.Arg, e.Err)
+}
+
+func (e *BuilderError) Unwrap() error {
+	return e.Err
+}
+
+// Err returns the misconfigurations of the Sling and of the Sling it was
+// copied from (see New), joined with errors.Join, or nil. Builder methods
+// which can't apply their arguments, such as Path with an unparsable path,
+// record a *BuilderError rather than failing silently, and Request returns
+// Err before building requests.
+func (s *Sling) Err() error {
+	return errors.Join(s.errs...)
+}
+
+// addErr records the misconfiguration of method with arg.
+func (s *Sling) addErr(method, arg string, err error) {
+	s.errs = append(s.errs, &BuilderError{Method: method, Arg: arg, Err: err})
+}
+
+// isStruct reports whether v is a struct or a pointer to one.
+func isStruct(v interface{}) bool {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
@@ -0,0 +1,66 @@
+package sling
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// CacheKeyFunc returns the cache key of a request, see ETagCache.Key and
+// MemoizeKey. Requests with equal keys share cached responses, so keys must
+// tell apart the principals of requests returning different responses.
+type CacheKeyFunc func(req *http.Request) string
+
+// CacheKey returns a CacheKeyFunc keying requests on their URL with the
+// query parameters sorted, the values of headers, and the digest of their
+// principal: the tenant (see TenantKey), the bearer token of
+// SetBearerAuthFunc and the Authorization header.
+//
+//	cache.Key = sling.CacheKey("Accept-Language", "X-API-Key")
+func CacheKey(headers ...string) CacheKeyFunc {
+	return func(req *http.Request) string {
+		u := *req.URL
+		u.RawQuery = u.Query().Encode()
+		var b strings.Builder
+		b.WriteString(u.String())
+		for _, name := range headers {
+			b.WriteString("\n" + http.CanonicalHeaderKey(name) + ": " + strings.Join(req.Header.Values(name), ", "))
+		}
+		principal := TenantFromContext(req.Context()) + "\n" + req.Header.Get(hdrAuthorizationKey)
+		if token, ok := req.Context().Value(bearerTokenKey{}).(string); ok {
+			principal += "\n" + token
+		}
+		digest := sha256.Sum256([]byte(principal))
+		b.WriteString("\n" + hex.EncodeToString(digest[:]))
+		return b.String()
+	}
+}
+
+// urlMatcher reports whether a cached URL is invalidated.
+type urlMatcher func(u *url.URL) bool
+
+// matchPattern matches the URLs whose path matches pattern (see path.Match).
+func matchPattern(pattern string) urlMatcher {
+	return func(u *url.URL) bool {
+		ok, _ := path.Match(pattern, u.Path)
+		return ok
+	}
+}
+
+// matchURL matches the URLs with the scheme, host and path of rawURL, and
+// its query if it has one. Invalid URLs match nothing.
+func matchURL(rawURL string) urlMatcher {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return func(*url.URL) bool { return false }
+	}
+	return func(u *url.URL) bool {
+		if u.Scheme != target.Scheme || u.Host != target.Host || u.Path != target.Path {
+			return false
+		}
+		return target.RawQuery == "" || u.Query().Encode() == target.Query().Encode()
+	}
+}
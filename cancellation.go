@@ -0,0 +1,80 @@
+package sling
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// CancellationReason is why a Sling subsystem gave up on a request, see
+// CancellationError.
+type CancellationReason int
+
+const (
+	// CancelTimeout is the expiry of a PerRequest Timeout.
+	CancelTimeout CancellationReason = iota + 1
+	// CancelAdaptiveTimeout is the expiry of a timeout applied by
+	// AdaptiveTimeout.
+	CancelAdaptiveTimeout
+	// CancelThrottled is a request shed by a ThrottleDoer failing fast.
+	CancelThrottled
+)
+
+var cancellationReasonNames = map[CancellationReason]string{
+	CancelTimeout:         "timeout",
+	CancelAdaptiveTimeout: "adaptive timeout",
+	CancelThrottled:       "throttled",
+}
+
+func (r CancellationReason) String() string {
+	if name, ok := cancellationReasonNames[r]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// CancellationError is returned (wrapped) for requests cancelled by a Sling
+// subsystem rather than by their caller, so callers and logs can tell "we
+// gave up" from caller initiated cancellations:
+//
+//	var cancelled *sling.CancellationError
+//	if errors.As(err, &cancelled) && cancelled.Reason == sling.CancelTimeout {
+//		...
+//	}
+//
+// Err is the error the request failed with, e.g. wrapping
+// context.DeadlineExceeded, so errors.Is keeps working.
+type CancellationError struct {
+	Reason CancellationReason
+	Err    error
+}
+
+func (e *CancellationError) Error() string {
+	return "sling: request cancelled (" + e.Reason.String() + "): " + e.Err.Error()
+}
+
+func (e *CancellationError) Unwrap() error {
+	return e.Err
+}
+
+// withTimeoutReason returns a copy of ctx done after timeout, with reason as
+// its cause.
+func withTimeoutReason(ctx context.Context, timeout time.Duration, reason CancellationReason) (context.Context, context.CancelFunc) {
+	return context.WithTimeoutCause(ctx, timeout, &CancellationError{Reason: reason, Err: context.DeadlineExceeded})
+}
+
+// withCancellationReason wraps err, a failure caused by ctx being done, in
+// a CancellationError if a Sling subsystem cancelled ctx.
+func withCancellationReason(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	if !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	var cancelled *CancellationError
+	if errors.As(err, &cancelled) || !errors.As(context.Cause(ctx), &cancelled) {
+		return err
+	}
+	return &CancellationError{Reason: cancelled.Reason, Err: err}
+}
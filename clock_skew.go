@@ -0,0 +1,59 @@
+package sling
+
+import (
+	"net/http"
+	"time"
+)
+
+// ClockSkew returns how far the server clock, read from the response Date
+// header, is ahead of the local clock when the response was received
+// (negative when it is behind), reporting false without a valid Date
+// header. Date headers have a one second resolution, so smaller skews are
+// not meaningful.
+func (r *Response) ClockSkew() (time.Duration, bool) {
+	if r.Response == nil || r.received.IsZero() {
+		return 0, false
+	}
+	date, err := http.ParseTime(r.Header.Get("Date"))
+	if err != nil {
+		return 0, false
+	}
+	return date.Sub(r.received.Truncate(time.Second)), true
+}
+
+// CheckClockSkew logs responses whose clock skew (see Response.ClockSkew)
+// exceeds threshold in either direction with logger, which helps debugging
+// signature based authentication or token expiry failures caused by a
+// drifting clock. A threshold <= 0 disables the check.
+func (s *Sling) CheckClockSkew(threshold time.Duration, logger Logger) *Sling {
+	s.checkMutable()
+	if threshold <= 0 {
+		s.clockSkew = nil
+		return s
+	}
+	if logger == nil {
+		logger = NewDefaultLogger()
+	}
+	s.clockSkew = &clockSkewCheck{threshold: threshold, logger: logger}
+	return s
+}
+
+// clockSkewCheck holds the settings of Sling.CheckClockSkew.
+type clockSkewCheck struct {
+	threshold time.Duration
+	logger    Logger
+}
+
+// check logs the response if its clock skew exceeds the threshold.
+func (c *clockSkewCheck) check(req *http.Request, resp *Response) {
+	skew, ok := resp.ClockSkew()
+	if !ok || (skew < c.threshold && skew > -c.threshold) {
+		return
+	}
+	c.logger.WithContext(req.Context()).WithFields(Fields{
+		"method":    req.Method,
+		"url":       req.URL,
+		"skew":      skew,
+		"threshold": c.threshold,
+	}).Error("server clock skew exceeds threshold")
+}
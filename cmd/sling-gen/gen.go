@@ -0,0 +1,568 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// spec is the subset of an OpenAPI 3 document used for generation.
+type spec struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas    map[string]*schema    `json:"schemas"`
+		Parameters map[string]*parameter `json:"parameters"`
+	} `json:"components"`
+
+	operations []*operation
+}
+
+type operation struct {
+	OperationID string       `json:"operationId"`
+	Summary     string       `json:"summary"`
+	Parameters  []*parameter `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]mediaType `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]mediaType `json:"content"`
+	} `json:"responses"`
+
+	method, path string
+}
+
+type parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *schema `json:"schema"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref         string             `json:"$ref"`
+	Type        schemaType         `json:"type"`
+	Format      string             `json:"format"`
+	Description string             `json:"description"`
+	Properties  map[string]*schema `json:"properties"`
+	Required    []string           `json:"required"`
+	Items       *schema            `json:"items"`
+}
+
+// schemaType is the JSON Schema type, the first non null one of OpenAPI 3.1
+// type arrays.
+type schemaType string
+
+func (t *schemaType) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*t = schemaType(s)
+		return nil
+	}
+	var types []string
+	if err := json.Unmarshal(b, &types); err != nil {
+		return err
+	}
+	for _, typ := range types {
+		if typ != "null" {
+			*t = schemaType(typ)
+			break
+		}
+	}
+	return nil
+}
+
+// slingMethods maps the operation keys of path items to Sling methods.
+var slingMethods = map[string]string{
+	"get":     "Get",
+	"put":     "Put",
+	"post":    "Post",
+	"delete":  "Delete",
+	"options": "Options",
+	"head":    "Head",
+	"patch":   "Patch",
+	"trace":   "Trace",
+}
+
+// parseSpec parses a JSON encoded OpenAPI 3 document and collects its
+// operations sorted by path and method.
+func parseSpec(b []byte) (*spec, error) {
+	doc := new(spec)
+	if err := json.Unmarshal(b, doc); err != nil {
+		return nil, err
+	}
+	for path, item := range doc.Paths {
+		var shared []*parameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &shared); err != nil {
+				return nil, fmt.Errorf("%s parameters: %v", path, err)
+			}
+		}
+		for method, raw := range item {
+			if _, ok := slingMethods[method]; !ok {
+				continue
+			}
+			op := &operation{method: method, path: path}
+			if err := json.Unmarshal(raw, op); err != nil {
+				return nil, fmt.Errorf("%s %s: %v", method, path, err)
+			}
+			params, err := doc.mergeParameters(shared, op.Parameters)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %v", method, path, err)
+			}
+			op.Parameters = params
+			doc.operations = append(doc.operations, op)
+		}
+	}
+	sort.Slice(doc.operations, func(i, j int) bool {
+		a, b := doc.operations[i], doc.operations[j]
+		if a.path != b.path {
+			return a.path < b.path
+		}
+		return a.method < b.method
+	})
+	return doc, nil
+}
+
+// mergeParameters resolves parameter refs and overrides path item
+// parameters with operation parameters of the same name and location.
+func (doc *spec) mergeParameters(shared, own []*parameter) ([]*parameter, error) {
+	var params []*parameter
+	index := make(map[string]int)
+	for _, p := range append(append([]*parameter{}, shared...), own...) {
+		if p.Ref != "" {
+			resolved, ok := doc.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]
+			if !ok {
+				return nil, fmt.Errorf("undefined parameter $ref %q", p.Ref)
+			}
+			p = resolved
+		}
+		key := p.In + " " + p.Name
+		if i, ok := index[key]; ok {
+			params[i] = p
+			continue
+		}
+		index[key] = len(params)
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+type generator struct {
+	doc     *spec
+	buf     bytes.Buffer
+	imports map[string]bool
+	// pathParams is true once an operation has path parameters, see
+	// pathSegmentSource.
+	pathParams bool
+	err        error
+}
+
+// generate returns the formatted source of the client for doc.
+func generate(doc *spec, pkg string) ([]byte, error) {
+	g := &generator{doc: doc, imports: map[string]bool{
+		"encoding/json": true,
+		"fmt":           true,
+	}}
+	g.buf.WriteString(clientSource)
+	for _, name := range sortedKeys(doc.Components.Schemas) {
+		if goName(name) == "APIError" || goName(name) == "Client" {
+			return nil, fmt.Errorf("schema %q clashes with the generated %s type", name, goName(name))
+		}
+		s := doc.Components.Schemas[name]
+		g.comment(goName(name), s.Description)
+		g.printf("type %s %s\n\n", goName(name), g.goType(s))
+	}
+	for _, op := range doc.operations {
+		g.operation(op)
+	}
+	if g.pathParams {
+		g.imports["net/url"] = true
+		g.imports["strings"] = true
+		g.buf.WriteString(pathSegmentSource)
+	}
+	if g.err != nil {
+		return nil, g.err
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by sling-gen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	for _, path := range sortedKeys(g.imports) {
+		fmt.Fprintf(&src, "%q\n", path)
+	}
+	fmt.Fprintf(&src, "\n%q\n)\n\n", slingImportPath)
+	src.Write(g.buf.Bytes())
+	return format.Source(src.Bytes())
+}
+
+const slingImportPath = "github.com/anhdhbn/sling"
+
+const clientSource = `// Client calls the API operations with a sling.Sling.
+type Client struct {
+	sling *sling.Sling
+}
+
+// NewClient returns a Client sending requests with s, whose base URL should
+// end with a "/".
+func NewClient(s *sling.Sling) *Client {
+	return &Client{sling: s}
+}
+
+// APIError is returned for responses with a non 2xx status.
+type APIError struct {
+	StatusCode int
+	Body       json.RawMessage
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+`
+
+// pathSegmentSource escapes path parameters as single segments, like
+// sling.PathSegments, so values such as ".." or "a/b" can't change the path.
+const pathSegmentSource = `// pathSegment escapes v as a single path segment, including dot segments and
+// colons.
+func pathSegment(v interface{}) string {
+	segment := fmt.Sprint(v)
+	if segment == "." || segment == ".." {
+		return strings.Repeat("%2E", len(segment))
+	}
+	return strings.ReplaceAll(url.PathEscape(segment), ":", "%3A")
+}
+`
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// comment prints the description of name as a doc comment.
+func (g *generator) comment(name, description string) {
+	if description == "" {
+		return
+	}
+	lines := strings.Split(strings.TrimSpace(description), "\n")
+	g.printf("// %s: %s\n", name, lines[0])
+	for _, line := range lines[1:] {
+		g.printf("// %s\n", line)
+	}
+}
+
+// goType returns the Go type of s, inlining object schemas as structs.
+func (g *generator) goType(s *schema) string {
+	if s == nil {
+		return "json.RawMessage"
+	}
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		if _, ok := g.doc.Components.Schemas[name]; !ok && g.err == nil {
+			g.err = fmt.Errorf("undefined schema $ref %q", s.Ref)
+		}
+		return goName(name)
+	}
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			g.imports["time"] = true
+			return "time.Time"
+		}
+		return "string"
+	case "integer":
+		if s.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if s.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.goType(s.Items)
+	case "object", "":
+		if len(s.Properties) > 0 {
+			return g.structType(s)
+		}
+		if s.Type == "object" {
+			return "map[string]interface{}"
+		}
+	}
+	return "interface{}"
+}
+
+func (g *generator) structType(s *schema) string {
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+	}
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	for _, name := range sortedKeys(s.Properties) {
+		prop := s.Properties[name]
+		if prop.Description != "" {
+			for _, line := range strings.Split(strings.TrimSpace(prop.Description), "\n") {
+				fmt.Fprintf(&b, "// %s\n", line)
+			}
+		}
+		tag := name
+		if !required[name] {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&b, "%s %s `json:%q`\n", goName(name), g.goType(prop), tag)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// namedType returns the Go type of s, declaring inline object schemas as a
+// type called name, described by doc.
+func (g *generator) namedType(name, doc string, s *schema) string {
+	if s.Ref == "" && len(s.Properties) > 0 {
+		g.printf("// %s is the %s.\ntype %s %s\n\n", name, doc, name, g.goType(s))
+		return name
+	}
+	return g.goType(s)
+}
+
+func (g *generator) operation(op *operation) {
+	name := operationName(op)
+	var pathParams, queryParams []*parameter
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			pathParams = append(pathParams, p)
+		case "query":
+			queryParams = append(queryParams, p)
+		}
+	}
+
+	if len(queryParams) > 0 {
+		g.printf("// %sParams are the query parameters of %s.\ntype %sParams struct {\n", name, name, name)
+		for _, p := range queryParams {
+			tag := p.Name
+			if !p.Required {
+				tag += ",omitempty"
+			}
+			g.printf("%s %s `url:%q`\n", goName(p.Name), g.goType(p.Schema), tag)
+		}
+		g.printf("}\n\n")
+	}
+	var bodyType, respType string
+	if op.RequestBody != nil {
+		if media, ok := jsonMediaType(op.RequestBody.Content); ok && media.Schema != nil {
+			bodyType = g.namedType(name+"Request", "request body of "+name, media.Schema)
+		}
+	}
+	for _, status := range sortedKeys(op.Responses) {
+		if !strings.HasPrefix(status, "2") {
+			continue
+		}
+		if media, ok := jsonMediaType(op.Responses[status].Content); ok && media.Schema != nil {
+			respType = g.namedType(name+"Response", "success response of "+name, media.Schema)
+			break
+		}
+	}
+
+	args := []string{"ctx context.Context"}
+	vars := make(map[string]string, len(pathParams))
+	for _, p := range pathParams {
+		vars[p.Name] = varName(p.Name)
+		args = append(args, vars[p.Name]+" "+g.goType(p.Schema))
+	}
+	if len(queryParams) > 0 {
+		args = append(args, "params *"+name+"Params")
+	}
+	if bodyType != "" {
+		args = append(args, "body *"+bodyType)
+	}
+	results := "(*sling.Response, error)"
+	if respType != "" {
+		results = "(*" + respType + ", *sling.Response, error)"
+	}
+	g.imports["context"] = true
+
+	g.printf("// %s calls %s %s.\n", name, strings.ToUpper(op.method), op.path)
+	if op.Summary != "" {
+		g.printf("//\n// %s\n", strings.ReplaceAll(strings.TrimSpace(op.Summary), "\n", "\n// "))
+	}
+	g.printf("func (c *Client) %s(%s) %s {\n", name, strings.Join(args, ", "), results)
+	g.newSling(op, vars, len(queryParams) > 0, bodyType != "")
+	g.printf("apiErr := new(APIError)\n")
+	if respType != "" {
+		g.printf("value := new(%s)\n", respType)
+		g.printf("resp, err := s.Receive(value, &apiErr.Body)\n")
+		g.printf("if err != nil {\nreturn nil, resp, err\n}\n")
+		g.printf("if resp.StatusCode < 200 || resp.StatusCode > 299 {\napiErr.StatusCode = resp.StatusCode\nreturn nil, resp, apiErr\n}\n")
+		g.printf("return value, resp, nil\n}\n\n")
+		if op.method == "get" && strings.HasPrefix(respType, "[]") {
+			g.pages(op, name, respType, args, vars, len(queryParams) > 0)
+		}
+		return
+	}
+	g.printf("resp, err := s.Receive(nil, &apiErr.Body)\n")
+	g.printf("if err != nil {\nreturn resp, err\n}\n")
+	g.printf("if resp.StatusCode < 200 || resp.StatusCode > 299 {\napiErr.StatusCode = resp.StatusCode\nreturn resp, apiErr\n}\n")
+	g.printf("return resp, nil\n}\n\n")
+}
+
+// newSling prints the statements building the Sling s of op.
+func (g *generator) newSling(op *operation, vars map[string]string, params, body bool) {
+	g.printf("s := c.sling.New().%s(%s).SetContext(ctx)\n", slingMethods[op.method], g.pathExpr(op.path, vars))
+	if params {
+		g.printf("if params != nil {\ns.QueryStruct(params)\n}\n")
+	}
+	if body {
+		g.printf("if body != nil {\ns.BodyJSON(body)\n}\n")
+	}
+}
+
+// pages prints the pagination method of the list operation op, following
+// the rel="next" links of its responses (see sling.Response.NextPage).
+func (g *generator) pages(op *operation, name, respType string, args []string, vars map[string]string, params bool) {
+	args = append(args, "page func(items "+respType+") bool")
+	g.printf("// %sPages calls %s and follows the rel=\"next\" links of its\n", name, name)
+	g.printf("// responses (see sling.Response.NextPage), calling page with the items of\n")
+	g.printf("// each page until it returns false or the last page is reached.\n")
+	g.printf("func (c *Client) %sPages(%s) error {\n", name, strings.Join(args, ", "))
+	g.newSling(op, vars, params, false)
+	g.printf("for {\n")
+	g.printf("apiErr := new(APIError)\n")
+	g.printf("var items %s\n", respType)
+	g.printf("resp, err := s.Receive(&items, &apiErr.Body)\n")
+	g.printf("if err != nil {\nreturn err\n}\n")
+	g.printf("if resp.StatusCode < 200 || resp.StatusCode > 299 {\napiErr.StatusCode = resp.StatusCode\nreturn apiErr\n}\n")
+	g.printf("next := resp.NextPage()\n")
+	g.printf("if !page(items) || next == \"\" {\nreturn nil\n}\n")
+	g.printf("s = c.sling.New().Get(next).SetContext(ctx)\n")
+	g.printf("}\n}\n\n")
+}
+
+// pathExpr returns a Go expression of path relative to the base URL, with
+// {name} placeholders replaced by the values of vars escaped as single
+// segments.
+func (g *generator) pathExpr(template string, vars map[string]string) string {
+	path := strings.TrimPrefix(template, "/")
+	var parts []string
+	for path != "" {
+		start := strings.IndexByte(path, '{')
+		end := strings.IndexByte(path, '}')
+		if start < 0 || end < start {
+			parts = append(parts, strconv.Quote(path))
+			break
+		}
+		if start > 0 {
+			parts = append(parts, strconv.Quote(path[:start]))
+		}
+		name := path[start+1 : end]
+		v, ok := vars[name]
+		if !ok {
+			if g.err == nil {
+				g.err = fmt.Errorf("path %q: undeclared parameter %q", template, name)
+			}
+			v = varName(name)
+		}
+		g.pathParams = true
+		parts = append(parts, "pathSegment("+v+")")
+		path = path[end+1:]
+	}
+	if len(parts) == 0 {
+		return `""`
+	}
+	return strings.Join(parts, " + ")
+}
+
+// jsonMediaType picks the JSON media type of content.
+func jsonMediaType(content map[string]mediaType) (mediaType, bool) {
+	for _, contentType := range sortedKeys(content) {
+		if contentType == "application/json" || strings.HasSuffix(contentType, "+json") {
+			return content[contentType], true
+		}
+	}
+	return mediaType{}, false
+}
+
+// operationName returns the exported method name of op, from its
+// operationId or else its method and path, e.g. GetUsersByID.
+func operationName(op *operation) string {
+	if op.OperationID != "" {
+		return goName(op.OperationID)
+	}
+	name := goName(op.method)
+	for _, segment := range strings.Split(op.path, "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			name += "By" + goName(segment[1:len(segment)-1])
+		} else {
+			name += goName(segment)
+		}
+	}
+	return name
+}
+
+var initialisms = map[string]bool{"API": true, "HTTP": true, "ID": true, "JSON": true, "URL": true, "UUID": true}
+
+// goName returns an exported Go identifier for s, e.g. "user_id" -> "UserID".
+func goName(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, part := range parts {
+		if upper := strings.ToUpper(part); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// reservedVars are the identifiers used by generated method bodies.
+var reservedVars = map[string]bool{"c": true, "ctx": true, "params": true, "body": true, "s": true, "apiErr": true, "value": true, "resp": true, "err": true, "url": true, "fmt": true, "sling": true, "page": true, "items": true, "next": true}
+
+// varName returns an unexported Go identifier for s, e.g. "user_id" ->
+// "userID".
+func varName(s string) string {
+	name := goName(s)
+	if initialisms[name] {
+		name = strings.ToLower(name)
+	} else {
+		runes := []rune(name)
+		runes[0] = unicode.ToLower(runes[0])
+		name = string(runes)
+	}
+	if token.IsKeyword(name) || reservedVars[name] {
+		name += "Param"
+	}
+	return name
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGenerate_golden(t *testing.T) {
+	b, err := os.ReadFile("testdata/petstore.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := parseSpec(b)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	src, err := generate(doc, "petstore")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	// internal/petstore is compiled and tested as part of the module, so the
+	// golden file also checks that the generated code builds and works.
+	expected, err := os.ReadFile("internal/petstore/client.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(src) != string(expected) {
+		t.Errorf("generated code differs from internal/petstore/client.go, regenerate with\n"+
+			"go run . -spec testdata/petstore.json -package petstore -o internal/petstore/client.go\n%s", src)
+	}
+}
+
+func TestGenerate_errors(t *testing.T) {
+	cases := []struct {
+		spec     string
+		expected string
+	}{
+		{`{"paths": {"/a": {"get": {"responses": {"200": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Missing"}}}}}}}}}`,
+			`undefined schema $ref "#/components/schemas/Missing"`},
+		{`{"paths": {"/a/{id}": {"get": {}}}}`, `path "/a/{id}": undeclared parameter "id"`},
+		{`{"paths": {"/a": {"get": {"parameters": [{"$ref": "#/components/parameters/Missing"}]}}}}`,
+			`get /a: undefined parameter $ref "#/components/parameters/Missing"`},
+		{`{"components": {"schemas": {"Client": {"type": "object"}}}}`, `schema "Client" clashes with the generated Client type`},
+	}
+	for _, c := range cases {
+		doc, err := parseSpec([]byte(c.spec))
+		if err == nil {
+			_, err = generate(doc, "api")
+		}
+		if err == nil || err.Error() != c.expected {
+			t.Errorf("expected error %q, got %v", c.expected, err)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	cases := []struct {
+		input, goName, varName string
+	}{
+		{"user_id", "UserID", "userID"},
+		{"id", "ID", "id"},
+		{"petName", "PetName", "petName"},
+		{"2fa-code", "X2faCode", "x2faCode"},
+		{"type", "Type", "typeParam"},
+		{"body", "Body", "bodyParam"},
+	}
+	for _, c := range cases {
+		if got := goName(c.input); got != c.goName {
+			t.Errorf("expected goName %s, got %s", c.goName, got)
+		}
+		if got := varName(c.input); got != c.varName {
+			t.Errorf("expected varName %s, got %s", c.varName, got)
+		}
+	}
+}
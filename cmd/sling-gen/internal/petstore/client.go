@@ -0,0 +1,169 @@
+// Code generated by sling-gen. DO NOT EDIT.
+
+package petstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/anhdhbn/sling"
+)
+
+// Client calls the API operations with a sling.Sling.
+type Client struct {
+	sling *sling.Sling
+}
+
+// NewClient returns a Client sending requests with s, whose base URL should
+// end with a "/".
+func NewClient(s *sling.Sling) *Client {
+	return &Client{sling: s}
+}
+
+// APIError is returned for responses with a non 2xx status.
+type APIError struct {
+	StatusCode int
+	Body       json.RawMessage
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+type Error struct {
+	Code    int32  `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Pet: a pet in the store.
+type Pet struct {
+	// Time of birth.
+	BornAt time.Time `json:"born_at,omitempty"`
+	ID     int64     `json:"id"`
+	Name   string    `json:"name"`
+	Tag    string    `json:"tag,omitempty"`
+}
+
+// ListPetsParams are the query parameters of ListPets.
+type ListPetsParams struct {
+	Limit int32  `url:"limit,omitempty"`
+	Tag   string `url:"tag"`
+}
+
+// ListPets calls GET /pets.
+//
+// List all pets.
+func (c *Client) ListPets(ctx context.Context, params *ListPetsParams) (*[]Pet, *sling.Response, error) {
+	s := c.sling.New().Get("pets").SetContext(ctx)
+	if params != nil {
+		s.QueryStruct(params)
+	}
+	apiErr := new(APIError)
+	value := new([]Pet)
+	resp, err := s.Receive(value, &apiErr.Body)
+	if err != nil {
+		return nil, resp, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr.StatusCode = resp.StatusCode
+		return nil, resp, apiErr
+	}
+	return value, resp, nil
+}
+
+// ListPetsPages calls ListPets and follows the rel="next" links of its
+// responses (see sling.Response.NextPage), calling page with the items of
+// each page until it returns false or the last page is reached.
+func (c *Client) ListPetsPages(ctx context.Context, params *ListPetsParams, page func(items []Pet) bool) error {
+	s := c.sling.New().Get("pets").SetContext(ctx)
+	if params != nil {
+		s.QueryStruct(params)
+	}
+	for {
+		apiErr := new(APIError)
+		var items []Pet
+		resp, err := s.Receive(&items, &apiErr.Body)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			apiErr.StatusCode = resp.StatusCode
+			return apiErr
+		}
+		next := resp.NextPage()
+		if !page(items) || next == "" {
+			return nil
+		}
+		s = c.sling.New().Get(next).SetContext(ctx)
+	}
+}
+
+// CreatePetRequest is the request body of CreatePet.
+type CreatePetRequest struct {
+	Name string `json:"name"`
+	Tag  string `json:"tag,omitempty"`
+}
+
+// CreatePet calls POST /pets.
+func (c *Client) CreatePet(ctx context.Context, body *CreatePetRequest) (*Pet, *sling.Response, error) {
+	s := c.sling.New().Post("pets").SetContext(ctx)
+	if body != nil {
+		s.BodyJSON(body)
+	}
+	apiErr := new(APIError)
+	value := new(Pet)
+	resp, err := s.Receive(value, &apiErr.Body)
+	if err != nil {
+		return nil, resp, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr.StatusCode = resp.StatusCode
+		return nil, resp, apiErr
+	}
+	return value, resp, nil
+}
+
+// DeletePet calls DELETE /pets/{pet_id}.
+func (c *Client) DeletePet(ctx context.Context, petID int64) (*sling.Response, error) {
+	s := c.sling.New().Delete("pets/" + pathSegment(petID)).SetContext(ctx)
+	apiErr := new(APIError)
+	resp, err := s.Receive(nil, &apiErr.Body)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr.StatusCode = resp.StatusCode
+		return resp, apiErr
+	}
+	return resp, nil
+}
+
+// GetPetsByPetID calls GET /pets/{pet_id}.
+func (c *Client) GetPetsByPetID(ctx context.Context, petID int64) (*Pet, *sling.Response, error) {
+	s := c.sling.New().Get("pets/" + pathSegment(petID)).SetContext(ctx)
+	apiErr := new(APIError)
+	value := new(Pet)
+	resp, err := s.Receive(value, &apiErr.Body)
+	if err != nil {
+		return nil, resp, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr.StatusCode = resp.StatusCode
+		return nil, resp, apiErr
+	}
+	return value, resp, nil
+}
+
+// pathSegment escapes v as a single path segment, including dot segments and
+// colons.
+func pathSegment(v interface{}) string {
+	segment := fmt.Sprint(v)
+	if segment == "." || segment == ".." {
+		return strings.Repeat("%2E", len(segment))
+	}
+	return strings.ReplaceAll(url.PathEscape(segment), ":", "%3A")
+}
@@ -0,0 +1,86 @@
+package petstore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/anhdhbn/sling/slingtest"
+)
+
+func TestClient(t *testing.T) {
+	srv := slingtest.NewServer(t)
+	srv.On("GET", "/pets").Query("tag", "dog").Query("limit", "2").
+		ReplyJSON(200, []Pet{{ID: 1, Name: "rex", Tag: "dog"}})
+	srv.On("POST", "/pets").JSONBody(CreatePetRequest{Name: "tom"}).
+		ReplyJSON(201, Pet{ID: 2, Name: "tom"})
+	srv.On("GET", "/pets/3").ReplyBody(404, `{"code": 404, "message": "not found"}`)
+	srv.On("DELETE", "/pets/2").Reply(204)
+	client := NewClient(srv.Sling())
+	ctx := context.Background()
+
+	pets, _, err := client.ListPets(ctx, &ListPetsParams{Tag: "dog", Limit: 2})
+	if err != nil || len(*pets) != 1 || (*pets)[0].Name != "rex" {
+		t.Errorf("expected [rex], got %v (%v)", pets, err)
+	}
+	pet, resp, err := client.CreatePet(ctx, &CreatePetRequest{Name: "tom"})
+	if err != nil || pet.ID != 2 || resp.StatusCode != 201 {
+		t.Errorf("expected pet 2, got %v (%v)", pet, err)
+	}
+	_, _, err = client.GetPetsByPetID(ctx, 3)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 404 || string(apiErr.Body) != `{"code": 404, "message": "not found"}` {
+		t.Errorf("expected APIError with status 404, got %v", err)
+	}
+	resp, err = client.DeletePet(ctx, 2)
+	if err != nil || resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected 204, got %v (%v)", resp, err)
+	}
+}
+
+func TestClient_pages(t *testing.T) {
+	srv := slingtest.NewServer(t)
+	srv.On("GET", "/pets").Query("page", "2").
+		ReplyJSON(200, []Pet{{ID: 3, Name: "tom"}})
+	srv.On("GET", "/pets").Query("tag", "dog").
+		ReplyHeader("Link", `</pets?tag=dog&page=2>; rel="next"`).
+		ReplyJSON(200, []Pet{{ID: 1, Name: "rex"}, {ID: 2, Name: "max"}})
+	client := NewClient(srv.Sling())
+
+	var names []string
+	err := client.ListPetsPages(context.Background(), &ListPetsParams{Tag: "dog"}, func(pets []Pet) bool {
+		for _, pet := range pets {
+			names = append(names, pet.Name)
+		}
+		return true
+	})
+	if err != nil || strings.Join(names, ",") != "rex,max,tom" {
+		t.Errorf("expected rex,max,tom, got %v (%v)", names, err)
+	}
+	// page stops the pagination
+	pages := 0
+	err = client.ListPetsPages(context.Background(), &ListPetsParams{Tag: "dog"}, func(pets []Pet) bool {
+		pages++
+		return false
+	})
+	if err != nil || pages != 1 {
+		t.Errorf("expected a single page, got %d (%v)", pages, err)
+	}
+}
+
+func TestPathSegment(t *testing.T) {
+	cases := map[interface{}]string{
+		int64(7): "7",
+		"a/b":    "a%2Fb",
+		"..":     "%2E%2E",
+		".":      "%2E",
+		"a:b":    "a%3Ab",
+	}
+	for v, expected := range cases {
+		if segment := pathSegment(v); segment != expected {
+			t.Errorf("%v: expected %q, got %q", v, expected, segment)
+		}
+	}
+}
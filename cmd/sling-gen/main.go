@@ -0,0 +1,62 @@
+// Command sling-gen generates a typed Go client built on sling from a JSON
+// encoded OpenAPI 3 document.
+//
+// Usage:
+//
+//	sling-gen -spec openapi.json -package petstore -o client.go
+//
+// or from a go:generate directive:
+//
+//	//go:generate go run github.com/anhdhbn/sling/cmd/sling-gen -spec openapi.json -package petstore -o client.go
+//
+// The generated file declares a type for every component schema, a params
+// struct for the query parameters of each operation and a Client with one
+// method per operation. GET operations returning an array also get a Pages
+// method following the rel="next" links of their responses (see
+// sling.Response.NextPage). Responses with a non 2xx status are returned as
+// an *APIError holding the raw body. Operation paths are resolved relative
+// to the base URL of the Sling passed to NewClient, which should end with a
+// "/".
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	specPath := flag.String("spec", "", "path of the JSON encoded OpenAPI 3 document")
+	pkg := flag.String("package", "client", "package name of the generated code")
+	out := flag.String("o", "", "output file (default stdout)")
+	flag.Parse()
+
+	if *specPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*specPath, *pkg, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "sling-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(specPath, pkg, out string) error {
+	b, err := os.ReadFile(specPath)
+	if err != nil {
+		return err
+	}
+	doc, err := parseSpec(b)
+	if err != nil {
+		return err
+	}
+	src, err := generate(doc, pkg)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
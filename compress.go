@@ -0,0 +1,48 @@
+package sling
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// ContentEncoding is a Content-Encoding applied to request bodies, see
+// CompressBody.
+type ContentEncoding string
+
+// Gzip compresses request bodies with gzip.
+const Gzip ContentEncoding = "gzip"
+
+// CompressBody compresses the body of new requests, whatever their
+// BodyProvider, and sets their Content-Encoding header:
+//
+//	s.Post("documents").CompressBody(sling.Gzip).BodyJSON(document)
+//
+// The compressed body is buffered, so requests have a Content-Length and can
+// be retried. An empty encoding disables compression; unsupported encodings
+// are ignored.
+func (s *Sling) CompressBody(encoding ContentEncoding) *Sling {
+	s.checkMutable()
+	switch encoding {
+	case "", Gzip:
+		s.bodyEncoding = encoding
+	}
+	return s
+}
+
+// compressBody returns body gzip compressed, closing body if it is an
+// io.Closer.
+func compressBody(body io.Reader) (io.Reader, error) {
+	if closer, ok := body.(io.Closer); ok {
+		defer closer.Close()
+	}
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	if _, err := io.Copy(zw, body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(buf.Bytes()), nil
+}
@@ -0,0 +1,53 @@
+package sling
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// SetCookie sends cookie with new requests (see Request()), replacing any
+// cookie with the same name set earlier, e.g. by a parent Sling. Use
+// CookieJar for cookies set by servers.
+func (s *Sling) SetCookie(cookie *http.Cookie) *Sling {
+	s.checkMutable()
+	if cookie == nil {
+		return s
+	}
+	cookies := make([]*http.Cookie, 0, len(s.cookies)+1)
+	for _, c := range s.cookies {
+		if c.Name != cookie.Name {
+			cookies = append(cookies, c)
+		}
+	}
+	s.cookies = append(cookies, cookie)
+	return s
+}
+
+// SetCookies sends cookies with new requests, see SetCookie.
+func (s *Sling) SetCookies(cookies ...*http.Cookie) *Sling {
+	for _, cookie := range cookies {
+		s.SetCookie(cookie)
+	}
+	return s
+}
+
+// CookieJar stores the cookies set by servers in jar and sends them with
+// matching requests, for session based APIs (login flows, CSRF tokens):
+//
+//	jar, _ := cookiejar.New(nil)
+//	s := sling.New().Base("https://app.example.com/").CookieJar(jar)
+//
+// The jar is set on a copy of the http.Client of the Sling's HttpWrapper
+// (see Doer), so other Slings sharing the client keep their own cookies. A
+// nil jar disables cookie storage.
+func (s *Sling) CookieJar(jar http.CookieJar) *Sling {
+	s.checkMutable()
+	s.configureWrapper("CookieJar", fmt.Sprintf("%T", jar), func(h *HttpWrapper) *HttpWrapper {
+		c := h.clone()
+		client := *h.http
+		client.Jar = jar
+		c.http = &client
+		return c
+	})
+	return s
+}
@@ -0,0 +1,169 @@
+package sling
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CsvDecoder decodes CSV (text/csv) responses into a *[][]string, holding
+// every record including the header, or into a pointer to a slice of structs
+// (or struct pointers), with one element per record after the header. Struct
+// fields are matched to header columns by their csv tag, or by name ignoring
+// case without tag; `csv:"-"` fields and unknown columns are skipped:
+//
+//	type Row struct {
+//		Day   string  `csv:"day"`
+//		Total float64 `csv:"total"`
+//	}
+//	var rows []Row
+//	resp, err := api.New().Get("reports/daily.csv").ResponseDecoder(sling.CsvDecoder{}).ReceiveSuccess(&rows)
+//
+// Fields may be strings, booleans, integers, floats or implement
+// encoding.TextUnmarshaler.
+type CsvDecoder struct {
+	// Comma is the field delimiter, ',' if zero.
+	Comma rune
+}
+
+// Decode decodes the Response Body into the value pointed to by v.
+func (d CsvDecoder) Decode(data []byte, v interface{}) error {
+	return d.DecodeReader(bytes.NewReader(data), v)
+}
+
+// DecodeReader decodes the CSV read from r into the value pointed to by v.
+func (d CsvDecoder) DecodeReader(r io.Reader, v interface{}) error {
+	reader := csv.NewReader(r)
+	if d.Comma != 0 {
+		reader.Comma = d.Comma
+	}
+	if records, ok := v.(*[][]string); ok {
+		all, err := reader.ReadAll()
+		*records = all
+		return err
+	}
+
+	slice := reflect.ValueOf(v)
+	if slice.Kind() != reflect.Pointer || slice.IsNil() || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("sling: cannot decode CSV into %T", v)
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("sling: cannot decode CSV into %T", v)
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		slice.SetLen(0)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	columns := csvColumns(structType, header)
+	rows := reflect.MakeSlice(slice.Type(), 0, 0)
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		elem := reflect.New(structType)
+		for i, field := range columns {
+			if field == nil || i >= len(record) {
+				continue
+			}
+			fieldValue, err := elem.Elem().FieldByIndexErr(field)
+			if err != nil {
+				// field promoted through a nil embedded pointer
+				continue
+			}
+			if err := setCSVField(fieldValue, record[i]); err != nil {
+				return fmt.Errorf("sling: CSV record %d, column %q: %w", n, header[i], err)
+			}
+		}
+		if elemType.Kind() != reflect.Pointer {
+			elem = elem.Elem()
+		}
+		rows = reflect.Append(rows, elem)
+	}
+	slice.Set(rows)
+	return nil
+}
+
+// csvColumns returns the index of the struct field of each header column,
+// nil for unmatched columns.
+func csvColumns(structType reflect.Type, header []string) [][]int {
+	columns := make([][]int, len(header))
+	for _, field := range reflect.VisibleFields(structType) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name, tagged := field.Tag.Lookup("csv")
+		if name == "-" {
+			continue
+		}
+		name, _, _ = strings.Cut(name, ",")
+		for i, column := range header {
+			column = strings.TrimSpace(column)
+			if columns[i] == nil && ((tagged && name != "" && column == name) || ((!tagged || name == "") && strings.EqualFold(column, field.Name))) {
+				columns[i] = field.Index
+			}
+		}
+	}
+	return columns
+}
+
+// setCSVField parses value into field.
+func setCSVField(field reflect.Value, value string) error {
+	if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+	value = strings.TrimSpace(value)
+	if value == "" && field.Kind() != reflect.String {
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
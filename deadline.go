@@ -0,0 +1,117 @@
+package sling
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Deadline hint headers, see PropagateDeadline.
+const (
+	// RequestTimeoutHeader carries the remaining time in milliseconds.
+	RequestTimeoutHeader = "X-Request-Timeout"
+	// GrpcTimeoutHeader carries the remaining time in the gRPC format, an
+	// integer of at most 8 digits followed by a unit among H, M, S, m, u and
+	// n, e.g. "1500m".
+	GrpcTimeoutHeader = "Grpc-Timeout"
+)
+
+// PropagateDeadline sends the time remaining before the request context
+// deadline in header (RequestTimeoutHeader, GrpcTimeoutHeader or any other
+// name, formatted like RequestTimeoutHeader), so that upstreams can stop
+// working on requests their caller gave up on. Requests without deadline
+// are sent without the header. An empty header disables propagation.
+func (s *Sling) PropagateDeadline(header string) *Sling {
+	s.checkMutable()
+	s.deadlineHeader = http.CanonicalHeaderKey(header)
+	return s
+}
+
+// withDeadlineHint returns req with the deadline hint header set from its
+// context, leaving req untouched.
+func (s *Sling) withDeadlineHint(req *http.Request) *http.Request {
+	if s.deadlineHeader == "" {
+		return req
+	}
+	deadline, ok := req.Context().Deadline()
+	if !ok {
+		return req
+	}
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return req
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(s.deadlineHeader, formatDeadlineHint(s.deadlineHeader, remaining))
+	return req
+}
+
+// ParseDeadlineHint parses the deadline hint header of h, as sent by
+// PropagateDeadline, reporting false when it is missing or invalid. It lets
+// servers and test doubles built alongside sling clients read the hint.
+func ParseDeadlineHint(h http.Header, header string) (time.Duration, bool) {
+	header = http.CanonicalHeaderKey(header)
+	value := h.Get(header)
+	if value == "" {
+		return 0, false
+	}
+	d, err := parseDeadlineHint(header, value)
+	return d, err == nil
+}
+
+// DeadlineHint parses the deadline hint header of the response, see
+// ParseDeadlineHint.
+func (r *Response) DeadlineHint(header string) (time.Duration, bool) {
+	if r.Response == nil {
+		return 0, false
+	}
+	return ParseDeadlineHint(r.Header, header)
+}
+
+// grpcTimeoutUnits are the gRPC timeout units, finest first.
+var grpcTimeoutUnits = []struct {
+	unit byte
+	d    time.Duration
+}{
+	{'n', time.Nanosecond},
+	{'u', time.Microsecond},
+	{'m', time.Millisecond},
+	{'S', time.Second},
+	{'M', time.Minute},
+	{'H', time.Hour},
+}
+
+func formatDeadlineHint(header string, d time.Duration) string {
+	if header != GrpcTimeoutHeader {
+		// round up so that sub-millisecond deadlines are not sent as 0
+		return strconv.FormatInt(int64((d+time.Millisecond-1)/time.Millisecond), 10)
+	}
+	for _, u := range grpcTimeoutUnits {
+		if n := (d + u.d - 1) / u.d; n < 1e8 {
+			return strconv.FormatInt(int64(n), 10) + string(u.unit)
+		}
+	}
+	return "99999999H"
+}
+
+func parseDeadlineHint(header, value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if header != GrpcTimeoutHeader {
+		ms, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || ms < 0 {
+			return 0, fmt.Errorf("sling: invalid %s %q", header, value)
+		}
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	if len(value) >= 2 && len(value) <= 9 {
+		n, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+		for _, u := range grpcTimeoutUnits {
+			if err == nil && n >= 0 && u.unit == value[len(value)-1] {
+				return time.Duration(n) * u.d, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("sling: invalid %s %q", header, value)
+}
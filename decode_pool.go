@@ -0,0 +1,100 @@
+package sling
+
+import (
+	"net/http"
+	"runtime"
+	"sync"
+)
+
+// DecodePool decodes responses of ReceiveAsync calls on at most a fixed
+// number of goroutines at once, so that request goroutines are not tied up
+// decoding large bodies and decoding parallelism is tunable independently
+// of the number of requests in flight.
+type DecodePool struct {
+	sem     chan struct{}
+	minSize int
+}
+
+// NewDecodePool returns a DecodePool running at most workers decodes at
+// once (runtime.GOMAXPROCS(0) if workers <= 0). Bodies smaller than minSize
+// bytes are decoded by the calling goroutine, where handing them over would
+// cost more than decoding them.
+func NewDecodePool(workers, minSize int) *DecodePool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return &DecodePool{sem: make(chan struct{}, workers), minSize: minSize}
+}
+
+// Future is the pending result of a ReceiveAsync call.
+type Future struct {
+	done     chan struct{}
+	response *Response
+	err      error
+}
+
+// Done returns a channel closed once the response has been decoded.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait waits until the response has been decoded and returns the results of
+// the call, as Receive would.
+func (f *Future) Wait() (*Response, error) {
+	<-f.done
+	return f.response, f.err
+}
+
+// ReceiveAsync sends the request like Receive, then decodes the response on
+// pool (inline if pool is nil) and returns a Future resolved once successV or
+// failureV are set. successV and failureV must not be read before then.
+//
+//	future := api.New().Get("exports/large").ReceiveAsync(pool, &export, &apiErr)
+//	...
+//	resp, err := future.Wait()
+//
+// The request is sent by the calling goroutine, which waits for a free
+// worker of pool before handing over the response, so at most as many
+// goroutines as workers are started. The decoding middlewares (see
+// UseDecoding) run around the decode, their next DecodingDoer returning the
+// received response, and profiling labels (see WithProfiling) apply to both
+// the send and the decode.
+func (s *Sling) ReceiveAsync(pool *DecodePool, successV, failureV interface{}, opts ...PerRequest) *Future {
+	f := &Future{done: make(chan struct{})}
+	req, err := s.Request()
+	if err != nil {
+		f.err = err
+		close(f.done)
+		return f
+	}
+	var response *Response
+	var decode func() error
+	s.profiled(req, func(req *http.Request) {
+		response, decode, err = s.send(req, successV, failureV, opts)
+	})
+	var once sync.Once
+	received := func(*http.Request, interface{}, interface{}) (*Response, error) {
+		once.Do(func() {
+			if err == nil && decode != nil {
+				err = decode()
+			}
+		})
+		return response, err
+	}
+	decodeLayer := func() {
+		s.profiled(req, func(req *http.Request) {
+			f.response, f.err = s.decodingDoer(received).DoDecode(req, successV, failureV)
+		})
+		close(f.done)
+	}
+	if pool == nil || decode == nil || (!response.Spooled() && len(response.RawData) < pool.minSize) {
+		decodeLayer()
+		return f
+	}
+	pool.sem <- struct{}{}
+	go func() {
+		defer func() { <-pool.sem }()
+		decodeLayer()
+	}()
+	return f
+}
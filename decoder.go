@@ -1,10 +1,17 @@
 package sling
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
 
+	"github.com/fxamacker/cbor/v2"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
 )
 
 // ResponseDecoder decodes http responses into struct values.
@@ -13,6 +20,55 @@ type ResponseDecoder interface {
 	Decode(bytes []byte, v interface{}) error
 }
 
+// ReaderDecoder is implemented by ResponseDecoders which can decode directly
+// from a reader, avoiding buffering bodies which were spooled to disk.
+type ReaderDecoder interface {
+	// DecodeReader decodes the body read from r into the value pointed to
+	// by v.
+	DecodeReader(r io.Reader, v interface{}) error
+}
+
+var (
+	// Buffered bodies larger than contextDecodeThreshold are streamed into
+	// ReaderDecoders through a contextReader, which reads at most
+	// contextReadChunk bytes between context checks.
+	contextDecodeThreshold = 64 << 10
+	contextReadChunk       = 32 << 10
+)
+
+// contextReader fails reads once its context is done. Reads are bounded to
+// contextReadChunk bytes, so long decodes check the context periodically.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if len(p) > contextReadChunk {
+		p = p[:contextReadChunk]
+	}
+	return r.r.Read(p)
+}
+
+// buffersWholeValue reports whether the DecodeReader method of decoder reads
+// a whole value into memory before decoding it, as CBOR decoders do.
+// Streaming an already buffered body into such decoders only copies it into
+// their buffer (growing it as it goes) before the same decoding work, which
+// a contextReader cannot interrupt, so RawData is decoded directly.
+func buffersWholeValue(decoder ResponseDecoder) bool {
+	_, ok := decoder.(CborDecoder)
+	return ok
+}
+
+// contextDecoder is implemented by ResponseDecoders which can stop decoding
+// buffered bodies once a context is done.
+type contextDecoder interface {
+	decodeContext(ctx context.Context, data []byte, v interface{}) error
+}
+
 // jsonDecoder decodes http response JSON into a JSON-tagged struct value.
 type jsonDecoder struct {
 }
@@ -23,6 +79,61 @@ func (d jsonDecoder) Decode(bytes []byte, v interface{}) error {
 	return json.Unmarshal(bytes, v)
 }
 
+// DecodeReader decodes the JSON read from r into the value pointed to by v.
+func (d jsonDecoder) DecodeReader(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// decodeContext decodes top level JSON arrays into the slice pointed to by v
+// one element at a time, failing with the context error once ctx is done.
+// Other values, and slices implementing json.Unmarshaler (e.g.
+// json.RawMessage), are decoded whole, as by Decode, since encoding/json
+// buffers and decodes a value in one go. Elements implementing
+// json.Unmarshaler are unmarshaled one at a time as usual.
+func (d jsonDecoder) decodeContext(ctx context.Context, data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return d.Decode(data, v)
+	}
+	if unmarshalsJSON(rv.Elem().Type()) {
+		return d.Decode(data, v)
+	}
+	if trimmed := bytes.TrimLeft(data, " \t\r\n"); len(trimmed) == 0 || trimmed[0] != '[' {
+		return d.Decode(data, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	slice := rv.Elem()
+	elems := reflect.New(slice.Type()).Elem()
+	for i := 0; dec.More(); i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		elems.Grow(1)
+		elems.SetLen(i + 1)
+		if err := dec.Decode(elems.Index(i).Addr().Interface()); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("sling: invalid JSON after top-level array at offset %d", dec.InputOffset())
+	}
+	slice.Set(elems)
+	return nil
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// unmarshalsJSON reports whether t or *t implements json.Unmarshaler.
+func unmarshalsJSON(t reflect.Type) bool {
+	return t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType)
+}
+
 // jsonDecoder decodes http response JSON into a JSON-tagged struct value.
 type JsonpbDecoder struct {
 }
@@ -32,3 +143,52 @@ type JsonpbDecoder struct {
 func (d JsonpbDecoder) Decode(bytes []byte, v interface{}) error {
 	return protojson.Unmarshal(bytes, v.(proto.Message))
 }
+
+// ProtoDecoder decodes binary protobuf (application/x-protobuf) responses
+// into proto.Message values.
+type ProtoDecoder struct {
+}
+
+// Decode decodes the Response Body into v, which must be a proto.Message.
+func (d ProtoDecoder) Decode(bytes []byte, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("sling: cannot decode protobuf into non proto.Message %T", v)
+	}
+	return proto.Unmarshal(bytes, msg)
+}
+
+// CborDecoder decodes CBOR (application/cbor) responses into cbor (or json)
+// tagged struct values.
+type CborDecoder struct {
+}
+
+// Decode decodes the Response Body into the value pointed to by v.
+func (d CborDecoder) Decode(bytes []byte, v interface{}) error {
+	return cbor.Unmarshal(bytes, v)
+}
+
+// DecodeReader decodes the CBOR read from r into the value pointed to by v.
+func (d CborDecoder) DecodeReader(r io.Reader, v interface{}) error {
+	return cbor.NewDecoder(r).Decode(v)
+}
+
+// YamlDecoder decodes YAML (application/yaml) responses into yaml tagged
+// struct values.
+type YamlDecoder struct {
+}
+
+// Decode decodes the Response Body into the value pointed to by v.
+func (d YamlDecoder) Decode(bytes []byte, v interface{}) error {
+	return yaml.Unmarshal(bytes, v)
+}
+
+// DecodeReader decodes the YAML read from r into the value pointed to by v.
+// Only the first document of a multi-document stream is decoded.
+func (d YamlDecoder) DecodeReader(r io.Reader, v interface{}) error {
+	err := yaml.NewDecoder(r).Decode(v)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
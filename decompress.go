@@ -0,0 +1,194 @@
+package sling
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// CompressionStats describes the Content-Encoding of a response body, see
+// Decompress.
+type CompressionStats struct {
+	// Encoding is the Content-Encoding of the body, empty for uncompressed
+	// bodies.
+	Encoding string
+	// WireBytes is the size of the body as received, -1 if unknown because
+	// the Transport decompressed it.
+	WireBytes int64
+	// DecodedBytes is the size of the decompressed body.
+	DecodedBytes int64
+}
+
+// Ratio returns the compression ratio of the body, DecodedBytes over
+// WireBytes, or 0 if unknown.
+func (c CompressionStats) Ratio() float64 {
+	if c.WireBytes <= 0 {
+		return 0
+	}
+	return float64(c.DecodedBytes) / float64(c.WireBytes)
+}
+
+// Decompress negotiates gzip and deflate compressed responses, sending
+// "Accept-Encoding: gzip, deflate" unless requests set Accept-Encoding,
+// and decompresses their bodies before they are verified, decrypted or
+// decoded. The encoding and sizes of each response are available from
+// Response.Compression and passed to observe, if not nil, e.g. to record
+// bandwidth savings per endpoint and spot uncompressed hot endpoints:
+//
+//	s.Decompress(func(req *http.Request, stats sling.CompressionStats) {
+//		wireBytes.WithLabelValues(req.URL.Path, stats.Encoding).Add(float64(stats.WireBytes))
+//	})
+//
+// With SpillToDisk, bodies are decompressed into a new temporary file when
+// their decompressed size exceeds the spill threshold, so compressed bodies
+// can't expand in memory. Without it, bodies decompressing to more than
+// 64 MiB fail with a *DecompressLimitError. Streamed responses (see NDJSON)
+// are left to the Transport.
+func (s *Sling) Decompress(observe func(req *http.Request, stats CompressionStats)) *Sling {
+	s.checkMutable()
+	s.decompress = &decompression{observe: observe}
+	return s
+}
+
+// maxDecompressedBytes bounds the bodies decompressed into memory when
+// responses aren't spilled to disk.
+var maxDecompressedBytes int64 = 64 << 20
+
+// DecompressLimitError is returned by Slings which Decompress responses
+// when a body decompresses to more than Max bytes and can't be spilled to
+// disk (see SpillToDisk).
+type DecompressLimitError struct {
+	Max int64
+}
+
+func (e *DecompressLimitError) Error() string {
+	return fmt.Sprintf("sling: decompressed body exceeds %d bytes", e.Max)
+}
+
+// decompression holds the Decompress settings of a Sling.
+type decompression struct {
+	observe func(req *http.Request, stats CompressionStats)
+}
+
+// negotiate returns req accepting compressed responses.
+func (d *decompression) negotiate(req *http.Request) *http.Request {
+	if isStreaming(req.Context()) || req.Header.Get("Accept-Encoding") != "" {
+		return req
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	return req
+}
+
+// Compression returns the encoding and sizes of the response body, and
+// false if the Sling does not Decompress responses.
+func (r *Response) Compression() (CompressionStats, bool) {
+	if r.compression == nil {
+		return CompressionStats{}, false
+	}
+	return *r.compression, true
+}
+
+// decompress decompresses the gzip or deflate encoded body of r, recording
+// its CompressionStats. Bodies with other encodings are left as is. Bodies
+// are decompressed with readOrSpool when spillThreshold is > 0.
+func (r *Response) decompress(req *http.Request, d *decompression, spillThreshold int64, spillDir string) error {
+	var body io.ReadSeeker
+	var size int64
+	if r.spool != nil {
+		body, size = r.Reader(), r.spool.size
+	} else {
+		body, size = bytes.NewReader(r.RawData), int64(len(r.RawData))
+	}
+	stats := CompressionStats{WireBytes: size, DecodedBytes: size}
+	if r.Uncompressed {
+		stats.Encoding, stats.WireBytes = "gzip", -1
+	}
+	encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+	if encoding != "" && encoding != "identity" {
+		stats.Encoding = encoding
+	}
+	var zr io.ReadCloser
+	var err error
+	switch encoding {
+	case "gzip", "x-gzip":
+		if size > 0 {
+			if zr, err = gzip.NewReader(body); err != nil {
+				r.ResetBody()
+				return err
+			}
+		}
+	case "deflate":
+		// deflate is zlib wrapped, but some servers send raw deflate
+		if size > 0 {
+			if zr, err = zlib.NewReader(body); err != nil {
+				if _, err := body.Seek(0, io.SeekStart); err != nil {
+					return err
+				}
+				zr = flate.NewReader(body)
+			}
+		}
+	}
+	if zr != nil {
+		if err := r.replaceBody(zr, spillThreshold, spillDir); err != nil {
+			return err
+		}
+		r.Uncompressed = true
+		r.Header.Del("Content-Encoding")
+		r.Header.Del("Content-Length")
+		stats.DecodedBytes = r.ContentLength
+	}
+	r.compression = &stats
+	if d.observe != nil {
+		d.observe(req, stats)
+	}
+	return nil
+}
+
+// replaceBody replaces the body of r by the decompressed body zr, which is
+// closed. Bodies larger than spillThreshold (or the threshold of spooled
+// bodies) are spooled to a new temporary file, and bodies larger than
+// maxDecompressedBytes fail without a threshold.
+func (r *Response) replaceBody(zr io.ReadCloser, spillThreshold int64, spillDir string) error {
+	defer zr.Close()
+	old := r.spool
+	if old != nil {
+		spillThreshold, spillDir = old.threshold, filepath.Dir(old.Name())
+	}
+	if spillThreshold <= 0 {
+		decoded, err := io.ReadAll(io.LimitReader(zr, maxDecompressedBytes+1))
+		if err != nil {
+			return err
+		}
+		if int64(len(decoded)) > maxDecompressedBytes {
+			return &DecompressLimitError{Max: maxDecompressedBytes}
+		}
+		r.RawData = decoded
+		r.ContentLength = int64(len(decoded))
+		r.ResetBody()
+		return nil
+	}
+	decoded, spool, err := readOrSpool(zr, spillThreshold, spillDir)
+	if old != nil {
+		old.Close()
+	}
+	if err != nil {
+		r.spool = nil
+		return err
+	}
+	r.spool, r.RawData = spool, decoded
+	if spool != nil {
+		r.Body = spool
+		r.ContentLength = spool.size
+	} else {
+		r.ContentLength = int64(len(decoded))
+	}
+	r.ResetBody()
+	return nil
+}
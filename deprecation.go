@@ -0,0 +1,108 @@
+package sling
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Deprecation describes the deprecation of an API resource announced by a
+// response with the Deprecation, Sunset (RFC 8594) and Link headers.
+type Deprecation struct {
+	// Date is when the resource was or will be deprecated, zero if the
+	// server only announced that it is deprecated ("Deprecation: true").
+	Date time.Time
+	// Sunset is when the resource will stop responding, zero if unknown.
+	Sunset time.Time
+	// Link is the URL of the documentation of the deprecation, from a Link
+	// header with rel="deprecation", or rel="sunset" otherwise.
+	Link string
+}
+
+// Deprecation returns the deprecation announced by the response headers,
+// reporting false if the response has neither a Deprecation nor a Sunset
+// header. Use OnDeprecation to be warned of deprecations.
+func (r *Response) Deprecation() (Deprecation, bool) {
+	if r.Response == nil {
+		return Deprecation{}, false
+	}
+	return parseDeprecation(r.Header)
+}
+
+// OnDeprecation calls warn with each response announcing a deprecation (see
+// Response.Deprecation), so teams learn about upcoming API removals from
+// their own client logs:
+//
+//	s.OnDeprecation(func(req *http.Request, d sling.Deprecation) {
+//		log.Printf("deprecated: %s %s, sunset %v, see %s", req.Method, req.URL.Path, d.Sunset, d.Link)
+//	})
+//
+// A nil warn disables the hook.
+func (s *Sling) OnDeprecation(warn func(req *http.Request, d Deprecation)) *Sling {
+	s.checkMutable()
+	s.onDeprecation = warn
+	return s
+}
+
+// parseDeprecation parses the deprecation headers of header.
+func parseDeprecation(header http.Header) (Deprecation, bool) {
+	deprecation, sunset := header.Get("Deprecation"), header.Get("Sunset")
+	if deprecation == "" && sunset == "" {
+		return Deprecation{}, false
+	}
+	var d Deprecation
+	switch {
+	case strings.HasPrefix(deprecation, "@"):
+		// structured date (RFC 9745), e.g. "@1688169599"
+		if sec, err := strconv.ParseInt(deprecation[1:], 10, 64); err == nil {
+			d.Date = time.Unix(sec, 0).UTC()
+		}
+	case deprecation != "" && !strings.EqualFold(deprecation, "true"):
+		d.Date, _ = http.ParseTime(deprecation)
+	}
+	if sunset != "" {
+		d.Sunset, _ = http.ParseTime(sunset)
+	}
+	links := parseLinks(header.Values("Link"))
+	if d.Link = links["deprecation"]; d.Link == "" {
+		d.Link = links["sunset"]
+	}
+	return d, true
+}
+
+// parseLinks returns the target of the first link of each relation type of
+// Link header values, e.g. `<https://api.example.com/docs>; rel="sunset"`.
+func parseLinks(values []string) map[string]string {
+	links := make(map[string]string)
+	for _, value := range values {
+		for value != "" {
+			start := strings.IndexByte(value, '<')
+			end := strings.IndexByte(value, '>')
+			if start < 0 || end < start {
+				break
+			}
+			target := value[start+1 : end]
+			value = value[end+1:]
+			params := value
+			if next := strings.IndexByte(value, '<'); next >= 0 {
+				params, value = value[:next], value[next:]
+			} else {
+				value = ""
+			}
+			for _, param := range strings.Split(params, ";") {
+				name, rels, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(strings.TrimSpace(name), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(rels), `",`)) {
+					rel = strings.ToLower(rel)
+					if _, ok := links[rel]; !ok {
+						links[rel] = target
+					}
+				}
+			}
+		}
+	}
+	return links
+}
@@ -0,0 +1,204 @@
+package sling
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ResponseDiff is the structured difference between two responses to the
+// same request, see CompareBases.
+type ResponseDiff struct {
+	// A and B are the compared responses.
+	A, B *Response
+	// Status is set when the status codes differ.
+	Status *StatusDiff
+	// Headers lists headers whose values differ, sorted by key.
+	Headers []HeaderDiff
+	// Body lists differences between the normalized JSON bodies, sorted by
+	// path. Non JSON bodies are compared as a whole at path "$".
+	Body []BodyDiff
+}
+
+// StatusDiff is a difference in status codes.
+type StatusDiff struct {
+	A, B int
+}
+
+// HeaderDiff is a difference in the values of the header Key.
+type HeaderDiff struct {
+	Key  string
+	A, B []string
+}
+
+// BodyDiff is a difference at a JSON path such as "$.items[0].id". A value
+// missing on one side is reported as nil.
+type BodyDiff struct {
+	Path string
+	A, B interface{}
+}
+
+// Equal reports whether no differences were found.
+func (d *ResponseDiff) Equal() bool {
+	return d.Status == nil && len(d.Headers) == 0 && len(d.Body) == 0
+}
+
+// String renders the differences one per line.
+func (d *ResponseDiff) String() string {
+	var lines []string
+	if d.Status != nil {
+		lines = append(lines, fmt.Sprintf("status: %d != %d", d.Status.A, d.Status.B))
+	}
+	for _, h := range d.Headers {
+		lines = append(lines, fmt.Sprintf("header %s: %q != %q", h.Key, h.A, h.B))
+	}
+	for _, b := range d.Body {
+		lines = append(lines, fmt.Sprintf("body %s: %v != %v", b.Path, b.A, b.B))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CompareBases sends the Sling's request to two base URLs (e.g. an old and a
+// new backend) and returns the differences between the responses. The scheme,
+// user info and host of the request URL are replaced by those of each base.
+// Headers listed in ignoreHeaders and the volatile Date and Content-Length
+// headers are not compared.
+func CompareBases(s *Sling, baseA, baseB string, ignoreHeaders ...string) (*ResponseDiff, error) {
+	a, err := receiveAt(s, baseA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := receiveAt(s, baseB)
+	if err != nil {
+		return nil, err
+	}
+	return DiffResponses(a, b, ignoreHeaders...), nil
+}
+
+// receiveAt sends the Sling's request with the host of base.
+func receiveAt(s *Sling, base string) (*Response, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	req, err := s.Request()
+	if err != nil {
+		return nil, err
+	}
+	req.URL.Scheme, req.URL.User, req.URL.Host = baseURL.Scheme, baseURL.User, baseURL.Host
+	req.Host = ""
+	return s.Do(req, nil, nil)
+}
+
+// DiffResponses returns the differences between the status, headers and
+// normalized JSON bodies of a and b. Headers listed in ignoreHeaders and the
+// volatile Date and Content-Length headers are not compared.
+func DiffResponses(a, b *Response, ignoreHeaders ...string) *ResponseDiff {
+	d := &ResponseDiff{A: a, B: b}
+	if a.StatusCode != b.StatusCode {
+		d.Status = &StatusDiff{A: a.StatusCode, B: b.StatusCode}
+	}
+
+	ignored := map[string]bool{"Date": true, "Content-Length": true}
+	for _, key := range ignoreHeaders {
+		ignored[http.CanonicalHeaderKey(key)] = true
+	}
+	keys := make(map[string]bool)
+	for key := range a.Header {
+		keys[key] = true
+	}
+	for key := range b.Header {
+		keys[key] = true
+	}
+	for key := range keys {
+		if !ignored[key] && !reflect.DeepEqual(a.Header[key], b.Header[key]) {
+			d.Headers = append(d.Headers, HeaderDiff{Key: key, A: a.Header[key], B: b.Header[key]})
+		}
+	}
+	sort.Slice(d.Headers, func(i, j int) bool { return d.Headers[i].Key < d.Headers[j].Key })
+
+	bodyA, errA := a.bytes()
+	bodyB, errB := b.bytes()
+	jsonA, errJSONA := normalizedJSON(bodyA)
+	jsonB, errJSONB := normalizedJSON(bodyB)
+	switch {
+	case errA != nil || errB != nil || errJSONA != nil || errJSONB != nil:
+		if !bytes.Equal(bodyA, bodyB) {
+			d.Body = []BodyDiff{{Path: "$", A: string(bodyA), B: string(bodyB)}}
+		}
+	default:
+		d.Body = diffJSON("$", jsonA, jsonB, nil)
+		sort.Slice(d.Body, func(i, j int) bool { return d.Body[i].Path < d.Body[j].Path })
+	}
+	return d
+}
+
+// normalizedJSON decodes data keeping numbers as json.Number, so that values
+// compare independently of formatting and key order.
+func normalizedJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	err := dec.Decode(&v)
+	return v, err
+}
+
+func diffJSON(path string, a, b interface{}, diffs []BodyDiff) []BodyDiff {
+	switch va := a.(type) {
+	case map[string]interface{}:
+		vb, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for key, childA := range va {
+			diffs = diffJSON(path+"."+key, childA, vb[key], diffs)
+		}
+		for key, childB := range vb {
+			if _, ok := va[key]; !ok {
+				diffs = append(diffs, BodyDiff{Path: path + "." + key, B: childB})
+			}
+		}
+		return diffs
+	case []interface{}:
+		vb, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(va) || i < len(vb); i++ {
+			var childA, childB interface{}
+			if i < len(va) {
+				childA = va[i]
+			}
+			if i < len(vb) {
+				childB = vb[i]
+			}
+			diffs = diffJSON(fmt.Sprintf("%s[%d]", path, i), childA, childB, diffs)
+		}
+		return diffs
+	}
+	if !jsonLeafEqual(a, b) {
+		diffs = append(diffs, BodyDiff{Path: path, A: a, B: b})
+	}
+	return diffs
+}
+
+// jsonLeafEqual compares JSON values, numbers by value so that 1 and 1.0 are
+// equal.
+func jsonLeafEqual(a, b interface{}) bool {
+	na, okA := a.(json.Number)
+	nb, okB := b.(json.Number)
+	if okA && okB {
+		if na == nb {
+			return true
+		}
+		fa, errA := na.Float64()
+		fb, errB := nb.Float64()
+		return errA == nil && errB == nil && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
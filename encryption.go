@@ -0,0 +1,161 @@
+package sling
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// BodyEncrypter encrypts request bodies, see Encrypt.
+type BodyEncrypter interface {
+	// Encrypt returns the encrypted plaintext. It may modify the request
+	// header, e.g. to set the id of the key.
+	Encrypt(plaintext []byte, header http.Header) ([]byte, error)
+}
+
+// ResponseDecrypter decrypts response bodies, see Decrypt.
+type ResponseDecrypter interface {
+	// Decrypt returns the decrypted ciphertext of a response with header.
+	Decrypt(ciphertext []byte, header http.Header) ([]byte, error)
+}
+
+// Encrypt encrypts request bodies with encrypter, after they are encoded by
+// their BodyProvider (and compressed, see CompressBody), for integrations
+// requiring application layer payload encryption. Encrypted bodies are
+// buffered. A nil encrypter disables encryption.
+func (s *Sling) Encrypt(encrypter BodyEncrypter) *Sling {
+	s.checkMutable()
+	s.encrypter = encrypter
+	return s
+}
+
+// Decrypt decrypts non empty response bodies with decrypter before they are
+// decoded (and after their signature is verified, see VerifySignatures).
+// Decrypted bodies are buffered into RawData. A nil decrypter disables
+// decryption.
+func (s *Sling) Decrypt(decrypter ResponseDecrypter) *Sling {
+	s.checkMutable()
+	s.decrypter = decrypter
+	return s
+}
+
+// encryptBody replaces the body of req by its encryption.
+func encryptBody(req *http.Request, encrypter BodyEncrypter) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	plaintext, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	ciphertext, err := encrypter.Encrypt(plaintext, req.Header)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(ciphertext))
+	req.Body = io.NopCloser(bytes.NewReader(ciphertext))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(ciphertext)), nil
+	}
+	return nil
+}
+
+// decrypt replaces the body of the response by its decryption.
+func (r *Response) decrypt(decrypter ResponseDecrypter) error {
+	if err := r.buffer(); err != nil {
+		return err
+	}
+	ciphertext, err := r.bytes()
+	if err != nil || len(ciphertext) == 0 {
+		return err
+	}
+	plaintext, err := decrypter.Decrypt(ciphertext, r.Header)
+	if err != nil {
+		return err
+	}
+	if r.spool != nil {
+		r.spool.Close()
+		r.spool = nil
+	}
+	r.RawData = plaintext
+	r.ContentLength = int64(len(plaintext))
+	r.ResetBody()
+	return nil
+}
+
+// AESGCM is a BodyEncrypter and ResponseDecrypter using AES-GCM, with a
+// random nonce prefixing each ciphertext. The id of the key is sent in, and
+// read from, the KeyIDHeader header:
+//
+//	keys := func(id string) ([]byte, error) { return keyring.Lookup(id) }
+//	aead := &sling.AESGCM{KeyID: "2024-01", KeyIDHeader: "X-Key-Id", Keys: keys}
+//	s.Encrypt(aead).Decrypt(aead)
+//
+// Content-Type headers are left as is, describing the plaintext.
+type AESGCM struct {
+	// KeyID identifies the key encrypting request bodies.
+	KeyID string
+	// KeyIDHeader carries the key id, "X-Key-Id" if empty.
+	KeyIDHeader string
+	// Keys returns the 16, 24 or 32 bytes AES key identified by keyID.
+	Keys func(keyID string) ([]byte, error)
+}
+
+var (
+	_ BodyEncrypter     = &AESGCM{}
+	_ ResponseDecrypter = &AESGCM{}
+)
+
+// Encrypt implements BodyEncrypter.
+func (a *AESGCM) Encrypt(plaintext []byte, header http.Header) ([]byte, error) {
+	aead, err := a.aead(a.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	header.Set(a.keyIDHeader(), a.KeyID)
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt implements ResponseDecrypter.
+func (a *AESGCM) Decrypt(ciphertext []byte, header http.Header) ([]byte, error) {
+	aead, err := a.aead(header.Get(a.keyIDHeader()))
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("sling: ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, nil)
+}
+
+func (a *AESGCM) keyIDHeader() string {
+	if a.KeyIDHeader == "" {
+		return "X-Key-Id"
+	}
+	return a.KeyIDHeader
+}
+
+func (a *AESGCM) aead(keyID string) (cipher.AEAD, error) {
+	if a.Keys == nil {
+		return nil, errors.New("sling: no AES-GCM keys")
+	}
+	key, err := a.Keys(keyID)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
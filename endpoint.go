@@ -0,0 +1,99 @@
+package sling
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// None marks the absent query or body of an Endpoint.
+type None struct{}
+
+var noneType = reflect.TypeOf(None{})
+
+// PathParams are the values substituted for the {name} placeholders of an
+// Endpoint path. Values are escaped as single path segments, see
+// PathSegments.
+type PathParams map[string]string
+
+// Endpoint declares an API operation: its method, path template, url tagged
+// query struct type Q, JSON request body type B and success response type R.
+// Use None for Q or B when the operation takes no query or body.
+//
+//	var getUser = sling.Endpoint[sling.None, sling.None, User]{Method: "GET", Path: "users/{id}"}
+//	var createUser = sling.Endpoint[sling.None, NewUser, User]{Method: "POST", Path: "users"}
+//
+//	user, _, err := getUser.Bind(api)(ctx, sling.PathParams{"id": "1"}, sling.None{}, sling.None{})
+type Endpoint[Q, B, R any] struct {
+	// Method is the HTTP method, GET if empty. Invalid methods are returned
+	// as a *BuilderError by calls.
+	Method string
+	// Path is resolved against the URL of the bound Sling and may contain
+	// {name} placeholders.
+	Path string
+}
+
+// EndpointFunc calls a bound Endpoint. It returns the decoded success
+// response, the received response and any error, a *StatusError for
+// responses which aren't successful (see WithSuccessDecider).
+type EndpointFunc[Q, B, R any] func(ctx context.Context, params PathParams, query Q, body B) (R, *Response, error)
+
+// Bind registers the Endpoint on s and returns a typed function calling it.
+// Each call builds its request from a copy of s, so headers, decoders and
+// the Doer set on s apply to all calls.
+func (e Endpoint[Q, B, R]) Bind(s *Sling) EndpointFunc[Q, B, R] {
+	hasQuery := reflect.TypeOf((*Q)(nil)).Elem() != noneType
+	hasBody := reflect.TypeOf((*B)(nil)).Elem() != noneType
+	return func(ctx context.Context, params PathParams, query Q, body B) (R, *Response, error) {
+		var value R
+		path, err := expandPath(e.Path, params)
+		if err != nil {
+			return value, nil, err
+		}
+		if ctx == nil {
+			ctx = s.Context()
+		}
+		method := e.Method
+		if method == "" {
+			method = "GET"
+		}
+		call := s.New().Method(method, path).SetContext(ctx)
+		if hasQuery {
+			call.QueryStruct(query)
+		}
+		if hasBody {
+			call.BodyJSON(body)
+		}
+		resp, err := call.ReceiveSuccess(&value)
+		if err == nil && resp != nil && !call.isSuccess(resp.Response) {
+			err = newStatusError(resp)
+		}
+		return value, resp, err
+	}
+}
+
+// expandPath substitutes the {name} placeholders of path with the params
+// escaped as single path segments.
+func expandPath(path string, params PathParams) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(path, '{')
+		if start < 0 {
+			b.WriteString(path)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(path[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("sling: path template %q: unclosed placeholder", path)
+		}
+		name := path[start+1 : start+end]
+		value, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("sling: path template: missing parameter %q", name)
+		}
+		b.WriteString(path[:start])
+		b.WriteString(escapePathSegment(value))
+		path = path[start+end+1:]
+	}
+}
@@ -0,0 +1,139 @@
+package sling
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultHealthAlpha is the EWMA smoothing factor of TrackEndpointHealth.
+const defaultHealthAlpha = 0.1
+
+// maxTrackedEndpoints bounds the EndpointStats of a Sling, see
+// TrackEndpointHealth.
+const maxTrackedEndpoints = 1000
+
+// EndpointStats is the health of an endpoint (host), as exponentially
+// weighted moving averages over its responses, see TrackEndpointHealth.
+type EndpointStats struct {
+	// Host is the host (and port) of the endpoint.
+	Host string
+	// Tenant is the tenant of the requests, see TenantKey.
+	Tenant string
+	// Requests is the number of requests sent to the endpoint.
+	Requests int64
+	// ErrorRate is the EWMA of failed requests, from 0 to 1. Transport
+	// errors and 5xx responses are failures.
+	ErrorRate float64
+	// Latency is the EWMA of the time to response headers.
+	Latency time.Duration
+}
+
+// Health returns a health score from 0 (always failing) to 1 (healthy).
+func (e EndpointStats) Health() float64 {
+	return 1 - e.ErrorRate
+}
+
+// endpointTracker tracks EndpointStats per host, shared by the Slings copied
+// from the Sling which enabled tracking.
+type endpointTracker struct {
+	alpha float64
+
+	mu    sync.Mutex
+	hosts map[endpointKey]*trackedEndpoint
+	// number of observations, ordering the endpoints by recency
+	seq uint64
+}
+
+// trackedEndpoint is the EndpointStats of an endpoint and the number of its
+// last observation.
+type trackedEndpoint struct {
+	EndpointStats
+	observed uint64
+}
+
+// endpointKey identifies the requests of a tenant to a host.
+type endpointKey struct {
+	host, tenant string
+}
+
+// TrackEndpointHealth tracks the error rate and latency of the endpoints
+// (hosts) requests are sent to, as EWMAs with smoothing factor alpha (the
+// weight of the latest response, 0.1 if not within (0, 1]), reported by
+// EndpointStats. Slings copied from s (see New) share its tracking, giving
+// operators, load balancing and failover logic a programmatic health
+// signal. Up to 1000 hosts and tenants are tracked, dropping the least
+// recently observed one for new ones.
+func (s *Sling) TrackEndpointHealth(alpha float64) *Sling {
+	s.checkMutable()
+	if alpha <= 0 || alpha > 1 {
+		alpha = defaultHealthAlpha
+	}
+	s.endpoints = &endpointTracker{alpha: alpha, hosts: make(map[endpointKey]*trackedEndpoint)}
+	return s
+}
+
+// EndpointStats returns the stats of the endpoints requests were sent to,
+// per tenant (see TenantKey), sorted by host and tenant, nil if endpoint
+// health isn't tracked (see TrackEndpointHealth).
+func (s *Sling) EndpointStats() []EndpointStats {
+	if s.endpoints == nil {
+		return nil
+	}
+	return s.endpoints.stats()
+}
+
+func (t *endpointTracker) stats() []EndpointStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := make([]EndpointStats, 0, len(t.hosts))
+	for _, e := range t.hosts {
+		stats = append(stats, e.EndpointStats)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Host != stats[j].Host {
+			return stats[i].Host < stats[j].Host
+		}
+		return stats[i].Tenant < stats[j].Tenant
+	})
+	return stats
+}
+
+// observe records the outcome of a request to req.URL.Host.
+func (t *endpointTracker) observe(req *http.Request, resp *http.Response, err error, latency time.Duration) {
+	failed := 0.0
+	if err != nil || (resp != nil && resp.StatusCode >= 500) {
+		failed = 1
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := endpointKey{host: req.URL.Host, tenant: TenantFromContext(req.Context())}
+	t.seq++
+	e, ok := t.hosts[key]
+	if !ok {
+		if len(t.hosts) >= maxTrackedEndpoints {
+			t.evictOldest()
+		}
+		// the first observation seeds the averages
+		stats := EndpointStats{Host: key.host, Tenant: key.tenant, Requests: 1, ErrorRate: failed, Latency: latency}
+		t.hosts[key] = &trackedEndpoint{EndpointStats: stats, observed: t.seq}
+		return
+	}
+	e.observed = t.seq
+	e.Requests++
+	e.ErrorRate += t.alpha * (failed - e.ErrorRate)
+	e.Latency += time.Duration(t.alpha * float64(latency-e.Latency))
+}
+
+// evictOldest drops the least recently observed endpoint.
+func (t *endpointTracker) evictOldest() {
+	var oldest endpointKey
+	observed := t.seq
+	for key, e := range t.hosts {
+		if e.observed < observed {
+			oldest, observed = key, e.observed
+		}
+	}
+	delete(t.hosts, oldest)
+}
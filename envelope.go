@@ -0,0 +1,83 @@
+package sling
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+// Envelope unwraps responses of APIs which wrap every payload in an object
+// such as {"data": ..., "error": ...}: the dataField of success responses is
+// decoded into successV and the errorField of failure responses into
+// failureV, with the Sling's ResponseDecoder. Success responses with a
+// non-null errorField also have it decoded into failureV, for APIs reporting
+// errors with a 200.
+//
+//	api := sling.New().Base("https://api.example.com/").Envelope("data", "error")
+//	resp, err := api.New().Get("users/1").Receive(user, apiErr)
+//
+// Missing or null fields leave the values untouched. An empty field name
+// decodes the whole body into the corresponding value, and two empty names
+// disable unwrapping. The envelope itself must be a JSON object.
+func (s *Sling) Envelope(dataField, errorField string) *Sling {
+	s.checkMutable()
+	if dataField == "" && errorField == "" {
+		s.envelope = nil
+		return s
+	}
+	s.envelope = &envelope{dataField: dataField, errorField: errorField}
+	return s
+}
+
+// envelope holds the field names set with Sling.Envelope.
+type envelope struct {
+	dataField, errorField string
+}
+
+// decodeResponse is decodeResponse unwrapping the envelope fields.
+func (e *envelope) decodeResponse(ctx context.Context, resp *Response, isSuccess SuccessDecider, decoder ResponseDecoder, transformers []ResponseTransformer, successV, failureV interface{}) error {
+	if !isSuccess(resp.Response) {
+		return decodeResponse(ctx, resp, isSuccess, e.decoder(decoder, e.errorField), nil, nil, failureV)
+	}
+	if err := decodeResponse(ctx, resp, isSuccess, e.decoder(decoder, e.dataField), transformers, successV, nil); err != nil {
+		return err
+	}
+	if failureV == nil || e.errorField == "" {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := resp.bytes()
+	if err != nil {
+		return err
+	}
+	return e.decoder(decoder, e.errorField).Decode(data, failureV)
+}
+
+// decoder returns a ResponseDecoder decoding field with decoder, or decoder
+// itself for the empty field.
+func (e *envelope) decoder(decoder ResponseDecoder, field string) ResponseDecoder {
+	if field == "" {
+		return decoder
+	}
+	return envelopeDecoder{decoder: decoder, field: field}
+}
+
+// envelopeDecoder decodes a field of a JSON envelope with decoder.
+type envelopeDecoder struct {
+	decoder ResponseDecoder
+	field   string
+}
+
+func (d envelopeDecoder) Decode(data []byte, v interface{}) error {
+	var fields Lazy
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	raw, ok := fields[d.field]
+	if !ok || bytes.Equal(raw, []byte("null")) {
+		return nil
+	}
+	return d.decoder.Decode(raw, v)
+}
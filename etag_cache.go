@@ -0,0 +1,222 @@
+package sling
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ETagCache caches responses carrying an ETag and revalidates them with
+// If-None-Match, answering 304 Not Modified responses with the cached body.
+// GET and HEAD requests are cached, as are POST requests to PostRoutes,
+// for APIs implementing searches as POST requests with a body:
+//
+//	cache := sling.NewETagCache("/v1/search", "/v1/*/query")
+//	api := sling.New().Base("https://api.example.com/").Use(cache.Middleware())
+//
+// Responses are keyed on the method and URL of their request, the hash of
+// its credential headers (Authorization, Cookie, X-API-Key and KeyHeaders)
+// and the hash of the body of POST requests, which is buffered. Spooled
+// responses (see SpillToDisk) are not cached. Requests setting their own
+// If-None-Match are revalidated as is, and their 304 responses only
+// answered from the cache if they match its entry. Key overrides the URL
+// and credentials part of keys, and writes can evict related responses
+// with Invalidate or InvalidateURL.
+type ETagCache struct {
+	// PostRoutes are the path patterns (see path.Match) of the POST
+	// endpoints to cache, e.g. "/v1/search".
+	PostRoutes []string
+	// MaxEntries bounds the number of cached responses, 1000 if 0.
+	MaxEntries int
+	// KeyHeaders are the headers besides the standard credential headers
+	// distinguishing the principals of requests, e.g. the API key header
+	// of SetAPIKey.
+	KeyHeaders []string
+	// Key returns the key of requests in place of their URL and credential
+	// headers, e.g. CacheKey("Accept-Language"). The method and body hash
+	// of POST requests still apply.
+	Key CacheKeyFunc
+
+	mu      sync.Mutex
+	entries map[etagKey]*etagEntry
+}
+
+type etagKey struct {
+	method, resource, credentials, bodyHash string
+}
+
+type etagEntry struct {
+	url     *url.URL
+	etag    string
+	status  int
+	header  http.Header
+	rawData []byte
+}
+
+// NewETagCache returns an ETagCache also caching POST requests to
+// postRoutes.
+func NewETagCache(postRoutes ...string) *ETagCache {
+	return &ETagCache{PostRoutes: postRoutes}
+}
+
+// Middleware returns a Middleware revalidating and caching responses with
+// c (see Use).
+func (c *ETagCache) Middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, []byte, error) {
+			key, ok, err := c.key(req)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !ok {
+				return next.Do(req)
+			}
+			entry := c.load(key)
+			if entry != nil && req.Header.Get(hdrIfNoneMatchKey) == "" {
+				req = req.Clone(req.Context())
+				req.Header.Set(hdrIfNoneMatchKey, entry.etag)
+			}
+			resp, rawData, err := next.Do(req)
+			if err != nil {
+				return resp, rawData, err
+			}
+			if resp.StatusCode == http.StatusNotModified && entry != nil && req.Header.Get(hdrIfNoneMatchKey) == entry.etag {
+				resp.Body.Close()
+				return entry.response(req), entry.rawData, nil
+			}
+			if etag := resp.Header.Get("ETag"); etag != "" && resp.StatusCode == http.StatusOK && rawData != nil {
+				c.store(key, &etagEntry{url: req.URL, etag: etag, status: resp.StatusCode, header: resp.Header.Clone(), rawData: rawData})
+			}
+			return resp, rawData, nil
+		})
+	}
+}
+
+// key returns the cache key of req, reporting false for requests which are
+// not cached. The body of cached POST requests is buffered to be hashed.
+func (c *ETagCache) key(req *http.Request) (etagKey, bool, error) {
+	key := etagKey{method: req.Method}
+	if c.Key != nil {
+		key.resource = c.Key(req)
+	} else {
+		key.resource, key.credentials = req.URL.String(), c.credentials(req.Header)
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		return key, true, nil
+	case http.MethodPost:
+		if !c.isPostRoute(req.URL.Path) {
+			return key, false, nil
+		}
+		payload, err := bufferBody(req)
+		if err != nil {
+			return key, false, err
+		}
+		key.bodyHash = sha256Hex(payload)
+		return key, true, nil
+	}
+	return key, false, nil
+}
+
+// credentials returns the hash of the credential headers of a request.
+func (c *ETagCache) credentials(header http.Header) string {
+	names := make([]string, 0, len(credentialHeaders)+len(c.KeyHeaders))
+	for name := range credentialHeaders {
+		names = append(names, name)
+	}
+	for _, name := range c.KeyHeaders {
+		names = append(names, http.CanonicalHeaderKey(name))
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		for _, value := range header.Values(name) {
+			fmt.Fprintf(&b, "%s: %s\n", name, value)
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return sha256Hex([]byte(b.String()))
+}
+
+func (c *ETagCache) isPostRoute(p string) bool {
+	for _, route := range c.PostRoutes {
+		if ok, _ := path.Match(route, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *ETagCache) load(key etagKey) *etagEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries[key]
+}
+
+func (c *ETagCache) store(key etagKey, entry *etagEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[etagKey]*etagEntry)
+	}
+	maxEntries := c.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxEntries {
+		// evict an arbitrary entry
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+	c.entries[key] = entry
+}
+
+// Invalidate evicts the responses to requests whose URL path matches
+// pattern (see path.Match), e.g. "/v1/users/*" after updating a user, and
+// returns their number.
+func (c *ETagCache) Invalidate(pattern string) int {
+	return c.invalidate(matchPattern(pattern))
+}
+
+// InvalidateURL evicts the responses to requests with the scheme, host and
+// path of rawURL, and its query if it has one, and returns their number.
+func (c *ETagCache) InvalidateURL(rawURL string) int {
+	return c.invalidate(matchURL(rawURL))
+}
+
+func (c *ETagCache) invalidate(match urlMatcher) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	evicted := 0
+	for key, entry := range c.entries {
+		if match(entry.url) {
+			delete(c.entries, key)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// response returns the cached response to req.
+func (e *etagEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(e.status) + " " + http.StatusText(e.status),
+		StatusCode:    e.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          replayBody(e.rawData),
+		ContentLength: int64(len(e.rawData)),
+		Request:       req,
+	}
+}
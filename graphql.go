@@ -0,0 +1,91 @@
+package sling
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// GraphQLLocation is a position in a GraphQL document.
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLError is an entry of the errors of a GraphQL response.
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Locations  []GraphQLLocation      `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// GraphQLErrors is returned for success responses to BodyGraphQL requests
+// which report errors. The data of partial results is still decoded.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+	return "sling: graphql: " + strings.Join(messages, "; ")
+}
+
+// graphQLRequest is the JSON body of a GraphQL request.
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+}
+
+// BodyGraphQL sets the Sling's body to a GraphQL request for query, with
+// optional variables and operationName, and decodes responses the GraphQL
+// way: the data field of success responses is decoded into successV and
+// their errors are returned as GraphQLErrors. Failure responses are decoded
+// into failureV as usual.
+//
+//	var data struct {
+//		User struct{ Name string } `json:"user"`
+//	}
+//	_, err := api.New().Post("graphql").
+//		BodyGraphQL(`query User($id: ID!) { user(id: $id) { name } }`, map[string]interface{}{"id": 1}, "User").
+//		ReceiveSuccess(&data)
+//
+// An empty query is ignored.
+func (s *Sling) BodyGraphQL(query string, variables map[string]interface{}, operationName string) *Sling {
+	if query == "" {
+		return s
+	}
+	s.BodyProvider(jsonBodyProvider{payload: graphQLRequest{Query: query, Variables: variables, OperationName: operationName}})
+	s.protocol = graphQLProtocol{}
+	return s
+}
+
+// graphQLProtocol decodes GraphQL responses.
+type graphQLProtocol struct{}
+
+var graphQLData = &envelope{dataField: "data"}
+
+func (graphQLProtocol) decodeResponse(ctx context.Context, resp *Response, isSuccess SuccessDecider, decoder ResponseDecoder, transformers []ResponseTransformer, successV, failureV interface{}) error {
+	if !isSuccess(resp.Response) {
+		return decodeResponse(ctx, resp, isSuccess, decoder, nil, nil, failureV)
+	}
+	if err := graphQLData.decodeResponse(ctx, resp, isSuccess, decoder, transformers, successV, nil); err != nil {
+		return err
+	}
+	data, err := resp.bytes()
+	if err != nil {
+		return err
+	}
+	var body struct {
+		Errors GraphQLErrors `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return err
+	}
+	if len(body.Errors) > 0 {
+		return body.Errors
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package sling
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// GrpcCode is a gRPC status code, see
+// https://grpc.github.io/grpc/core/md_doc_statuscodes.html
+type GrpcCode int
+
+// gRPC status codes.
+const (
+	GrpcOK GrpcCode = iota
+	GrpcCanceled
+	GrpcUnknown
+	GrpcInvalidArgument
+	GrpcDeadlineExceeded
+	GrpcNotFound
+	GrpcAlreadyExists
+	GrpcPermissionDenied
+	GrpcResourceExhausted
+	GrpcFailedPrecondition
+	GrpcAborted
+	GrpcOutOfRange
+	GrpcUnimplemented
+	GrpcInternal
+	GrpcUnavailable
+	GrpcDataLoss
+	GrpcUnauthenticated
+)
+
+var grpcCodeNames = [...]string{
+	"OK",
+	"Canceled",
+	"Unknown",
+	"InvalidArgument",
+	"DeadlineExceeded",
+	"NotFound",
+	"AlreadyExists",
+	"PermissionDenied",
+	"ResourceExhausted",
+	"FailedPrecondition",
+	"Aborted",
+	"OutOfRange",
+	"Unimplemented",
+	"Internal",
+	"Unavailable",
+	"DataLoss",
+	"Unauthenticated",
+}
+
+func (c GrpcCode) String() string {
+	if c >= 0 && int(c) < len(grpcCodeNames) {
+		return grpcCodeNames[c]
+	}
+	return "Code(" + strconv.Itoa(int(c)) + ")"
+}
+
+// gRPC status headers and trailers.
+const (
+	grpcStatusHeader  = "Grpc-Status"
+	grpcMessageHeader = "Grpc-Message"
+)
+
+// GrpcStatusError is returned for responses with a non OK grpc-status.
+type GrpcStatusError struct {
+	Code GrpcCode
+	// Message is the decoded grpc-message.
+	Message string
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+}
+
+func (e *GrpcStatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("sling: grpc status %s", e.Code)
+	}
+	return fmt.Sprintf("sling: grpc status %s: %s", e.Code, e.Message)
+}
+
+// CheckGrpcStatus makes Do return a *GrpcStatusError, without decoding the
+// response, when the grpc-status trailer (or header, for trailers-only
+// responses) of a response is not OK, for HTTP/2 services signalling errors
+// out of band from the body:
+//
+//	_, err := s.CheckGrpcStatus().Post("rpc").ReceiveSuccess(reply)
+//	var statusErr *sling.GrpcStatusError
+//	if errors.As(err, &statusErr) && statusErr.Code == sling.GrpcNotFound {
+//		...
+//	}
+//
+// Trailers of streamed responses (see NDJSON) are not checked, since they
+// are only received after the body.
+func (s *Sling) CheckGrpcStatus() *Sling {
+	s.checkMutable()
+	s.grpcStatus = true
+	return s
+}
+
+// GrpcStatus returns the *GrpcStatusError of a response with a non OK
+// grpc-status trailer or header, nil otherwise.
+func (r *Response) GrpcStatus() *GrpcStatusError {
+	header := r.Trailer
+	if header.Get(grpcStatusHeader) == "" {
+		header = r.Header
+	}
+	return grpcStatusError(header, r.StatusCode)
+}
+
+func grpcStatusError(header http.Header, statusCode int) *GrpcStatusError {
+	status := header.Get(grpcStatusHeader)
+	if status == "" {
+		return nil
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		code = int(GrpcUnknown)
+	}
+	if GrpcCode(code) == GrpcOK {
+		return nil
+	}
+	message := header.Get(grpcMessageHeader)
+	if unescaped, err := url.PathUnescape(message); err == nil {
+		message = unescaped
+	}
+	return &GrpcStatusError{Code: GrpcCode(code), Message: message, StatusCode: statusCode}
+}
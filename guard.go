@@ -0,0 +1,44 @@
+package sling
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrMutatedAfterUse is the panic value of builder calls on a guarded Sling
+// which already created requests, see GuardMutations.
+var ErrMutatedAfterUse = errors.New("sling: Sling modified after creating requests, derive a copy with New() instead")
+
+// mutationGuard enables the guard of GuardMutations for all Slings.
+var mutationGuard atomic.Bool
+
+// SetMutationGuard enables or disables GuardMutations for all Slings, e.g.
+// in tests or debug builds.
+func SetMutationGuard(enabled bool) {
+	mutationGuard.Store(enabled)
+}
+
+// GuardMutations makes builder calls panic with ErrMutatedAfterUse once the
+// Sling has created a request (see Request, Receive and Do). Slings shared
+// between goroutines should only be used to derive copies with New(), and
+// the guard catches code which modifies the shared Sling instead. Copies
+// made with New() are guarded too, but start unused.
+func (s *Sling) GuardMutations() *Sling {
+	s.checkMutable()
+	s.guarded = true
+	return s
+}
+
+// markUsed records that the Sling created a request.
+func (s *Sling) markUsed() {
+	if s.guarded || mutationGuard.Load() {
+		s.used.Store(true)
+	}
+}
+
+// checkMutable panics if the Sling is guarded and was used.
+func (s *Sling) checkMutable() {
+	if (s.guarded || mutationGuard.Load()) && s.used.Load() {
+		panic(ErrMutatedAfterUse)
+	}
+}
@@ -0,0 +1,236 @@
+package sling
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+var (
+	// Default limits of the HardenedJSONDecoder
+	defaultMaxDepth        = 64
+	defaultMaxStringLength = 1 << 20
+	defaultMaxArrayLength  = 100000
+)
+
+// DecodeLimitError is returned by the HardenedJSONDecoder when a response
+// exceeds one of its limits.
+type DecodeLimitError struct {
+	// Limit names the exceeded limit: "depth", "string length" or
+	// "array length".
+	Limit string
+	Max   int
+}
+
+func (e *DecodeLimitError) Error() string {
+	return fmt.Sprintf("sling: JSON exceeds max %s %d", e.Limit, e.Max)
+}
+
+// DuplicateKeyError is returned by the HardenedJSONDecoder when a JSON object
+// contains the same key more than once.
+type DuplicateKeyError struct {
+	Key string
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("sling: duplicate JSON key %q", e.Key)
+}
+
+// HardenedJSONDecoder decodes JSON responses from semi-trusted sources. Before
+// the standard decoding, the response is scanned and rejected if it nests
+// deeper than MaxDepth, contains strings longer than MaxStringLength, arrays
+// longer than MaxArrayLength or objects with duplicate keys. Keys of objects
+// decoded into structs are compared case-insensitively, as encoding/json
+// matches them to fields. Zero valued limits use the defaults (64, 1MiB and
+// 100000 respectively).
+type HardenedJSONDecoder struct {
+	MaxDepth        int
+	MaxStringLength int
+	MaxArrayLength  int
+}
+
+// Decode validates bytes against the decoder limits and decodes them into the
+// value pointed to by v.
+func (d HardenedJSONDecoder) Decode(bytes []byte, v interface{}) error {
+	if err := d.validate(bytes, reflect.TypeOf(v)); err != nil {
+		return err
+	}
+	return json.Unmarshal(bytes, v)
+}
+
+// jsonFrame tracks an open JSON object or array while validating.
+type jsonFrame struct {
+	object    bool
+	expectKey bool
+	keys      map[string]struct{}
+	length    int
+	// typ is the Go type the object or array decodes into, nil if unknown,
+	// and elem the type of its next value.
+	typ, elem reflect.Type
+}
+
+// key returns the key identifying key in the frame's object. Struct fields
+// match keys case-insensitively, so their keys are folded.
+func (f *jsonFrame) key(key string) string {
+	if f.typ != nil && f.typ.Kind() == reflect.Struct {
+		return strings.ToLower(key)
+	}
+	return key
+}
+
+// setKey sets the type of the value of key.
+func (f *jsonFrame) setKey(key string) {
+	f.elem = nil
+	if f.typ == nil {
+		return
+	}
+	switch f.typ.Kind() {
+	case reflect.Map:
+		f.elem = f.typ.Elem()
+	case reflect.Struct:
+		f.elem = jsonFieldType(f.typ, key)
+	}
+}
+
+// jsonFieldType returns the type of the field of the struct type t which
+// the JSON key decodes into, nil if none.
+func jsonFieldType(t reflect.Type, key string) reflect.Type {
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous && field.Type.Kind() == reflect.Struct {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.EqualFold(name, key) {
+			return field.Type
+		}
+	}
+	return nil
+}
+
+// containerType returns the type decoding a JSON object or array, without
+// pointers, or nil if unknown or decoded by a json.Unmarshaler.
+func containerType(t reflect.Type) reflect.Type {
+	for t != nil {
+		if unmarshalsJSON(t) {
+			return nil
+		}
+		if t.Kind() != reflect.Pointer {
+			return t
+		}
+		t = t.Elem()
+	}
+	return nil
+}
+
+// validate scans b, decoded into a value of type typ, for the decoder
+// limits and duplicate keys.
+func (d HardenedJSONDecoder) validate(b []byte, typ reflect.Type) error {
+	maxDepth := orDefault(d.MaxDepth, defaultMaxDepth)
+	maxString := orDefault(d.MaxStringLength, defaultMaxStringLength)
+	maxArray := orDefault(d.MaxArrayLength, defaultMaxArrayLength)
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var stack []*jsonFrame
+	top := func() *jsonFrame {
+		if len(stack) == 0 {
+			return nil
+		}
+		return stack[len(stack)-1]
+	}
+	// startValue counts array elements, endValue flips objects back to
+	// expecting a key.
+	startValue := func() error {
+		if f := top(); f != nil && !f.object {
+			f.length++
+			if f.length > maxArray {
+				return &DecodeLimitError{Limit: "array length", Max: maxArray}
+			}
+		}
+		return nil
+	}
+	endValue := func() {
+		if f := top(); f != nil && f.object {
+			f.expectKey = true
+		}
+	}
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if f := top(); f != nil && f.object && f.expectKey {
+			if key, ok := tok.(string); ok {
+				if len(key) > maxString {
+					return &DecodeLimitError{Limit: "string length", Max: maxString}
+				}
+				if _, dup := f.keys[f.key(key)]; dup {
+					return &DuplicateKeyError{Key: key}
+				}
+				f.keys[f.key(key)] = struct{}{}
+				f.setKey(key)
+				f.expectKey = false
+				continue
+			}
+		}
+		switch t := tok.(type) {
+		case json.Delim:
+			switch t {
+			case '{', '[':
+				if err := startValue(); err != nil {
+					return err
+				}
+				if len(stack) >= maxDepth {
+					return &DecodeLimitError{Limit: "depth", Max: maxDepth}
+				}
+				frame := &jsonFrame{object: t == '{', expectKey: t == '{', typ: typ}
+				if f := top(); f != nil {
+					frame.typ = f.elem
+				}
+				frame.typ = containerType(frame.typ)
+				if frame.object {
+					frame.keys = make(map[string]struct{})
+				} else if frame.typ != nil && (frame.typ.Kind() == reflect.Slice || frame.typ.Kind() == reflect.Array) {
+					frame.elem = frame.typ.Elem()
+				}
+				stack = append(stack, frame)
+			case '}', ']':
+				stack = stack[:len(stack)-1]
+				endValue()
+			}
+		case string:
+			if len(t) > maxString {
+				return &DecodeLimitError{Limit: "string length", Max: maxString}
+			}
+			if err := startValue(); err != nil {
+				return err
+			}
+			endValue()
+		default:
+			if err := startValue(); err != nil {
+				return err
+			}
+			endValue()
+		}
+	}
+}
+
+func orDefault(v, def int) int {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,142 @@
+package sling
+
+import (
+	"errors"
+	"mime"
+	"strings"
+)
+
+const (
+	hdrAcceptKey       = "Accept"
+	hdrIfNoneMatchKey  = "If-None-Match"
+	hdrCacheControlKey = "Cache-Control"
+)
+
+// ContentType sets the Content-Type header to the given media type, e.g.
+// "application/json; charset=utf-8". If the media type cannot be parsed, the
+// header is left unmodified and an error is returned by Request (see Err).
+func (s *Sling) ContentType(mediaType string) *Sling {
+	s.checkMutable()
+	if _, _, err := mime.ParseMediaType(mediaType); err != nil {
+		s.addErr("ContentType", mediaType, err)
+		return s
+	}
+	return s.SetHeader(hdrContentTypeKey, mediaType)
+}
+
+// Accept sets the Accept header to the given media ranges, e.g.
+// Accept("application/json", "text/*;q=0.5"). If any media range cannot be
+// parsed, the header is left unmodified and an error is returned by Request
+// (see Err).
+func (s *Sling) Accept(mediaRanges ...string) *Sling {
+	s.checkMutable()
+	if len(mediaRanges) == 0 {
+		return s
+	}
+	for _, mediaRange := range mediaRanges {
+		if _, _, err := mime.ParseMediaType(mediaRange); err != nil {
+			s.addErr("Accept", mediaRange, err)
+			return s
+		}
+	}
+	return s.SetHeader(hdrAcceptKey, strings.Join(mediaRanges, ", "))
+}
+
+// IfNoneMatch sets the If-None-Match header to the given entity tags. Bare
+// tags are quoted, so both `abc` and `"abc"` are accepted, as are weak tags
+// (`W/"abc"`) and "*". If any tag contains invalid characters, the header is
+// left unmodified and an error is returned by Request (see Err).
+func (s *Sling) IfNoneMatch(etags ...string) *Sling {
+	s.checkMutable()
+	if len(etags) == 0 {
+		return s
+	}
+	quoted := make([]string, len(etags))
+	for i, etag := range etags {
+		var ok bool
+		if quoted[i], ok = quoteETag(etag); !ok {
+			s.addErr("IfNoneMatch", etag, errors.New("invalid entity tag"))
+			return s
+		}
+	}
+	return s.SetHeader(hdrIfNoneMatchKey, strings.Join(quoted, ", "))
+}
+
+// quoteETag returns etag as a quoted entity tag, reporting false if it is
+// not a valid one.
+func quoteETag(etag string) (string, bool) {
+	if etag == "*" {
+		return etag, true
+	}
+	prefix := ""
+	if strings.HasPrefix(etag, `W/`) {
+		prefix, etag = `W/`, etag[2:]
+	}
+	if len(etag) >= 2 && strings.HasPrefix(etag, `"`) && strings.HasSuffix(etag, `"`) {
+		etag = etag[1 : len(etag)-1]
+	}
+	for i := 0; i < len(etag); i++ {
+		// etagc = %x21 / %x23-7E / obs-text
+		if c := etag[i]; c == '"' || c <= 0x20 || c == 0x7f {
+			return "", false
+		}
+	}
+	return prefix + `"` + etag + `"`, true
+}
+
+// CacheControl sets the Cache-Control header to the given directives, e.g.
+// CacheControl("no-cache", "max-age=0"). If any directive is not a token or
+// token=value pair, the header is left unmodified and an error is returned
+// by Request (see Err).
+func (s *Sling) CacheControl(directives ...string) *Sling {
+	s.checkMutable()
+	if len(directives) == 0 {
+		return s
+	}
+	for _, directive := range directives {
+		name, value, hasValue := strings.Cut(directive, "=")
+		if !isToken(name) || (hasValue && !isToken(value) && !isQuotedString(value)) {
+			s.addErr("CacheControl", directive, errors.New("invalid directive"))
+			return s
+		}
+	}
+	return s.SetHeader(hdrCacheControlKey, strings.Join(directives, ", "))
+}
+
+// Authorization sets the Authorization header to the given scheme and
+// credentials, e.g. Authorization("Token", token). If the scheme is not a
+// token or the credentials are empty or contain line breaks, the header is
+// left unmodified and an error, which doesn't include the credentials, is
+// returned by Request (see Err).
+func (s *Sling) Authorization(scheme, credentials string) *Sling {
+	s.checkMutable()
+	if !isToken(scheme) {
+		s.addErr("Authorization", scheme, errors.New("invalid scheme"))
+		return s
+	}
+	if credentials == "" || strings.ContainsAny(credentials, "\r\n") {
+		s.addErr("Authorization", scheme, errors.New("empty credentials or credentials with line breaks"))
+		return s
+	}
+	return s.SetHeader(hdrAuthorizationKey, scheme+" "+credentials)
+}
+
+// isToken reports whether s is a non-empty RFC 7230 token.
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= 0x20 || c >= 0x7f || strings.IndexByte(`"(),/:;<=>?@[\]{}`, c) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// isQuotedString reports whether s is a double quoted string without line
+// breaks.
+func isQuotedString(s string) bool {
+	return len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' && !strings.ContainsAny(s, "\r\n")
+}
@@ -0,0 +1,186 @@
+package sling
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HeaderStruct appends the headerStruct to the Sling's headerStructs. The
+// fields of the value pointed to by each headerStruct are encoded as headers
+// of new requests (see Request()), mirroring QueryStruct for header heavy
+// APIs:
+//
+//	type Conditions struct {
+//		IfMatch     string    `header:"If-Match,omitempty"`
+//		Since       time.Time `header:"If-Modified-Since,omitempty"`
+//		Preferences []string  `header:"Prefer"`
+//	}
+//
+// Only fields with a header tag are encoded, except embedded structs, whose
+// fields are encoded in turn. The omitempty option skips zero values and a
+// "-" tag skips the field. Slices add a header value per element, times are
+// rendered as HTTP dates and encoding.TextMarshaler and fmt.Stringer values
+// with their text. Encoded headers replace the values of their keys from
+// Headers, and are replaced by SetHeaderFunc values.
+// Values which aren't structs are ignored and an error is returned by
+// Request (see Err).
+func (s *Sling) HeaderStruct(headerStruct interface{}) *Sling {
+	s.checkMutable()
+	if headerStruct == nil {
+		return s
+	}
+	if !isStruct(headerStruct) {
+		s.addErr("HeaderStruct", fmt.Sprintf("%T", headerStruct), errors.New("not a struct"))
+		return s
+	}
+	s.headerStructs = append(s.headerStructs, headerStruct)
+	return s
+}
+
+// setHeaderStructs sets the headers of req encoded from the headerStructs.
+func setHeaderStructs(req *http.Request, headerStructs []interface{}) error {
+	for _, headerStruct := range headerStructs {
+		header, err := encodeHeader(headerStruct)
+		if err != nil {
+			return err
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+	}
+	return nil
+}
+
+// encodeHeader encodes the header tagged struct v.
+func encodeHeader(v interface{}) (http.Header, error) {
+	header := make(http.Header)
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return header, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("sling: header struct %T is not a struct", v)
+	}
+	return header, encodeHeaderFields(rv, header)
+}
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// encodeHeaderFields adds the header tagged fields of the struct rv to header.
+func encodeHeaderFields(rv reflect.Value, header http.Header) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("header")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := rv.Field(i)
+		omitEmpty := strings.Contains(opts, "omitempty")
+		if name == "" {
+			if !field.Anonymous {
+				continue
+			}
+			for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct && fv.Type() != timeType {
+				if err := encodeHeaderFields(fv, header); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+		values, err := headerValues(fv)
+		if err != nil {
+			return fmt.Errorf("sling: header field %s: %w", field.Name, err)
+		}
+		if len(values) == 0 && omitEmpty {
+			continue
+		}
+		key := http.CanonicalHeaderKey(name)
+		header.Del(key)
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+	return nil
+}
+
+// headerValues renders the value of a header field, one value per element of
+// slices and arrays. Nil pointers and interfaces have no values.
+func headerValues(fv reflect.Value) ([]string, error) {
+	for fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			return nil, nil
+		}
+		fv = fv.Elem()
+	}
+	if (fv.Kind() == reflect.Slice || fv.Kind() == reflect.Array) && fv.Type().Elem().Kind() != reflect.Uint8 {
+		values := make([]string, 0, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			elem, err := headerValues(fv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, elem...)
+		}
+		return values, nil
+	}
+	value, err := headerValue(fv)
+	if err != nil {
+		return nil, err
+	}
+	return []string{value}, nil
+}
+
+// headerValue renders a single header value.
+func headerValue(fv reflect.Value) (string, error) {
+	if fv.Type() == timeType {
+		return fv.Interface().(time.Time).UTC().Format(http.TimeFormat), nil
+	}
+	if fv.Type().Implements(textMarshalerType) {
+		text, err := fv.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	if fv.Type().Implements(stringerType) {
+		return fv.Interface().(fmt.Stringer).String(), nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(fv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(fv.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(fv.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(fv.Float(), 'f', -1, 64), nil
+	case reflect.Slice:
+		// []byte
+		return string(fv.Bytes()), nil
+	}
+	return "", fmt.Errorf("unsupported type %s", fv.Type())
+}
@@ -1,19 +1,92 @@
 package sling
 
 import (
+	"fmt"
 	"io"
 	"net/http"
+	"regexp"
+
+	otelhttp "go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
+// A regular expression to match the error returned by net/http when the
+// response headers exceed Transport.MaxResponseHeaderBytes. This error isn't
+// typed specifically so we resort to matching on the error string.
+var headerBytesErrorRe = regexp.MustCompile(`server response headers exceeded \d+ bytes`)
+
+// HeaderLimitError is returned when a response exceeds the configured header
+// size or count limits.
+type HeaderLimitError struct {
+	// Limit names the exceeded limit: "bytes" or "count".
+	Limit string
+	Max   int64
+	// Err is the underlying transport error, if any.
+	Err error
+}
+
+func (e *HeaderLimitError) Error() string {
+	return fmt.Sprintf("sling: response headers exceed max %s %d", e.Limit, e.Max)
+}
+
+func (e *HeaderLimitError) Unwrap() error {
+	return e.Err
+}
+
 type HttpWrapper struct {
 	http *http.Client
+	// transport is the base transport of http, cloned when the wrapper is
+	// reconfigured. It is nil for clients with custom RoundTrippers.
+	transport *http.Transport
+	// maxHeaderCount limits the number of response header values, 0 means
+	// no limit.
+	maxHeaderCount int
+	// bodies larger than spillThreshold bytes are spooled to a temporary
+	// file in spillDir, 0 means always buffer in memory.
+	spillThreshold int64
+	spillDir       string
 }
 
-func (h *HttpWrapper) Do(req *http.Request) (*http.Response, []byte, error) {
+// DoRaw sends req and returns the response with its body unread, see
+// RawDoer. Transport failures are classified (see NetError) and responses
+// exceeding the header limits are drained, closed and fail with a
+// *HeaderLimitError.
+func (h *HttpWrapper) DoRaw(req *http.Request) (*http.Response, error) {
 	resp, err := h.http.Do(req)
+	if err != nil {
+		if h.transport != nil && h.transport.MaxResponseHeaderBytes > 0 && headerBytesErrorRe.MatchString(err.Error()) {
+			return nil, &HeaderLimitError{Limit: "bytes", Max: h.transport.MaxResponseHeaderBytes, Err: err}
+		}
+		return nil, classifyNetError(err)
+	}
+	if h.maxHeaderCount > 0 && headerCount(resp.Header) > h.maxHeaderCount {
+		drainBody(resp.Body)
+		return nil, &HeaderLimitError{Limit: "count", Max: int64(h.maxHeaderCount)}
+	}
+	return resp, nil
+}
+
+// Do sends req with DoRaw and reads the response body, spooling large
+// bodies to disk (see SpillToDisk).
+func (h *HttpWrapper) Do(req *http.Request) (*http.Response, []byte, error) {
+	resp, err := h.DoRaw(req)
 	if err != nil {
 		return nil, nil, err
 	}
+	return readResponse(req, resp, h.spillThreshold, h.spillDir)
+}
+
+// readResponse reads the body of resp into memory, or into a temporary
+// file in spillDir when larger than spillThreshold bytes (if > 0), and
+// closes it. The bodies of streamed requests are left unread, see NDJSON.
+func readResponse(req *http.Request, resp *http.Response, spillThreshold int64, spillDir string) (*http.Response, []byte, error) {
+	if progress := progressFromContext(req.Context()); progress != nil {
+		resp.Body = &progressBody{ReadCloser: resp.Body, progress: progress, total: resp.ContentLength}
+	}
+	if isStreaming(req.Context()) {
+		// the body is read and closed by the caller, see NDJSON
+		resp.Body = &streamBody{ReadCloser: resp.Body}
+		return resp, nil, nil
+	}
 	// when err is nil, resp contains a non-nil resp.Body which must be closed
 	defer resp.Body.Close()
 
@@ -22,13 +95,144 @@ func (h *HttpWrapper) Do(req *http.Request) (*http.Response, []byte, error) {
 	// not read to completion and closed.
 	// See: https://golang.org/pkg/net/http/#Response
 	defer io.Copy(io.Discard, resp.Body)
+	if spillThreshold > 0 {
+		rawData, spool, err := readOrSpool(resp.Body, spillThreshold, spillDir)
+		if err != nil {
+			return nil, nil, classifyNetError(err)
+		}
+		if spool != nil {
+			resp.Body = spool
+			return resp, nil, nil
+		}
+		resp.Body = replayBody(rawData)
+		return resp, rawData, nil
+	}
 	rawData, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, classifyNetError(err)
 	}
+	// the body has been consumed, keep it readable for wrapping Doers
+	resp.Body = replayBody(rawData)
 	return resp, rawData, nil
 }
 
+// headerCount returns the number of values in header.
+func headerCount(header http.Header) int {
+	n := 0
+	for _, values := range header {
+		n += len(values)
+	}
+	return n
+}
+
 func NewHttpWrapper(client *http.Client) *HttpWrapper {
-	return &HttpWrapper{http: client}
+	h := &HttpWrapper{http: client}
+	if t, ok := client.Transport.(*http.Transport); ok {
+		h.transport = t
+	}
+	return h
+}
+
+// newTransportWrapper returns an HttpWrapper sending requests with a copy of
+// client through an OpenTelemetry instrumented transport.
+func newTransportWrapper(client http.Client, transport *http.Transport) *HttpWrapper {
+	client.Transport = otelhttp.NewTransport(transport)
+	return &HttpWrapper{http: &client, transport: transport}
+}
+
+// clone returns a shallow copy of the wrapper sharing its http.Client.
+func (h *HttpWrapper) clone() *HttpWrapper {
+	c := *h
+	return &c
+}
+
+// withTransport returns a copy of the wrapper using a clone of its transport
+// modified by configure. Wrappers of clients without a Transport start from a
+// clone of http.DefaultTransport, and wrappers of clients with custom
+// RoundTrippers can't be configured.
+func (h *HttpWrapper) withTransport(configure func(t *http.Transport)) (*HttpWrapper, error) {
+	base := h.transport
+	if base == nil {
+		if h.http.Transport != nil {
+			return nil, fmt.Errorf("cannot configure transport of custom RoundTripper %T", h.http.Transport)
+		}
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	t := base.Clone()
+	configure(t)
+	c := h.clone()
+	client := *h.http
+	client.Transport = otelhttp.NewTransport(t)
+	c.http, c.transport = &client, t
+	return c, nil
+}
+
+// reconfigureDoer applies configure to the HttpWrapper at the bottom of doer,
+// rebuilding any known wrapping Doers around the result. Custom Doers can't
+// be configured and are left to the caller.
+func reconfigureDoer(doer Doer, configure func(h *HttpWrapper) (*HttpWrapper, error)) (Doer, error) {
+	switch d := doer.(type) {
+	case nil:
+		return configure(defaultClient)
+	case *HttpWrapper:
+		return configure(d)
+	case *RetryDoer:
+		inner, err := reconfigureDoer(d.HTTPClient, configure)
+		if err != nil {
+			return nil, err
+		}
+		retry := *d
+		retry.HTTPClient = inner
+		return &retry, nil
+	case *ThrottleDoer:
+		inner, err := reconfigureDoer(d.Doer, configure)
+		if err != nil {
+			return nil, err
+		}
+		throttle := *d
+		throttle.Doer = inner
+		return &throttle, nil
+	}
+	return nil, fmt.Errorf("cannot configure transport of custom Doer %T", doer)
+}
+
+// spillSettings returns the spill threshold and directory (see SpillToDisk)
+// of the HttpWrapper of doer, below any RetryDoer or ThrottleDoer.
+func spillSettings(doer Doer) (int64, string) {
+	switch d := doer.(type) {
+	case *HttpWrapper:
+		return d.spillThreshold, d.spillDir
+	case *RetryDoer:
+		return spillSettings(d.HTTPClient)
+	case *ThrottleDoer:
+		return spillSettings(d.Doer)
+	}
+	return 0, ""
+}
+
+// configureWrapper replaces the HttpWrapper of the Sling's Doer by the copy
+// configure returns, see reconfigureDoer. Doers which can't be configured
+// are left as is and recorded as a builder error of method with arg.
+func (s *Sling) configureWrapper(method, arg string, configure func(h *HttpWrapper) *HttpWrapper) {
+	doer, err := reconfigureDoer(s.httpClient, func(h *HttpWrapper) (*HttpWrapper, error) {
+		return configure(h), nil
+	})
+	if err != nil {
+		s.addErr(method, arg, err)
+		return
+	}
+	s.httpClient = doer
+}
+
+// configureTransport modifies a clone of the transport of the Sling's
+// HttpWrapper with configure, see configureWrapper.
+func (s *Sling) configureTransport(method, arg string, configure func(t *http.Transport)) {
+	doer, err := reconfigureDoer(s.httpClient, func(h *HttpWrapper) (*HttpWrapper, error) {
+		return h.withTransport(configure)
+	})
+	if err != nil {
+		s.addErr(method, arg, err)
+		return
+	}
+	s.httpClient = doer
 }
@@ -0,0 +1,139 @@
+package sling
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+// RequestMethod returns the HTTP method of new requests.
+func (s *Sling) RequestMethod() string {
+	return s.method
+}
+
+// URL returns the URL of new requests, with the query parameters of the
+// Sling encoded (see Request()), but without any cache busting nonce (see
+// CacheBust). Returns any errors parsing the rawURL or encoding query
+// structs.
+func (s *Sling) URL() (*url.URL, error) {
+	return s.requestURL(false)
+}
+
+// requestURL returns the URL of new requests, with a cache busting nonce if
+// cacheBust is true and the Sling sets one.
+func (s *Sling) requestURL(cacheBust bool) (*url.URL, error) {
+	reqURL, err := url.Parse(s.rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := buildQueryParamUrl(reqURL, s.queryStructs, s.queryParams, s.queryEdits, s.timeFormat); err != nil {
+		return nil, err
+	}
+	if (cacheBust && s.cacheBustParam != "") || s.arrayEncoding != ArrayRepeat {
+		query := reqURL.Query()
+		if cacheBust && s.cacheBustParam != "" {
+			query.Set(s.cacheBustParam, cacheBustNonce())
+		}
+		reqURL.RawQuery = encodeQuery(query, s.arrayEncoding)
+	}
+	return reqURL, nil
+}
+
+// Headers returns a copy of the headers of new requests.
+func (s *Sling) Headers() http.Header {
+	return s.header.Clone()
+}
+
+// Query returns the query parameters of new requests, see URL.
+func (s *Sling) Query() (url.Values, error) {
+	reqURL, err := s.URL()
+	if err != nil {
+		return nil, err
+	}
+	return reqURL.Query(), nil
+}
+
+// String returns the method and URL of new requests, e.g.
+// "GET https://api.example.com/users?page=2". Credentials are masked, see
+// GoString.
+func (s *Sling) String() string {
+	return s.method + " " + s.displayURL()
+}
+
+// GoString returns a debug rendering of the method, URL, headers and body
+// of new requests, used by the %#v verb. The password of the URL user info,
+// API keys (see SetAPIKey) and the values of credential headers such as
+// Authorization and Cookie are masked, so the rendering can be logged.
+func (s *Sling) GoString() string {
+	body := "nil"
+	if s.bodyProvider != nil {
+		body = fmt.Sprintf("%T", s.bodyProvider)
+	}
+	return fmt.Sprintf("&sling.Sling{Method: %q, URL: %q, Header: %#v, Body: %s}", s.method, s.displayURL(), s.displayHeader(), body)
+}
+
+// maskedValue replaces credentials in renderings of Slings, as by
+// url.URL.Redacted.
+const maskedValue = "xxxxx"
+
+// credentialHeaders are the headers masked by GoString, besides the API key
+// headers of SetAPIKey.
+var credentialHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"X-Api-Key":           true,
+	"X-Auth-Token":        true,
+}
+
+// rawUserInfoRe matches the user info password of URLs which can't be
+// parsed.
+var rawUserInfoRe = regexp.MustCompile(`(//[^/@:]*:)[^/@]*@`)
+
+// displayURL returns the URL of new requests, or the raw URL if it is
+// invalid, with credentials masked.
+func (s *Sling) displayURL() string {
+	reqURL, err := s.URL()
+	if err != nil {
+		return rawUserInfoRe.ReplaceAllString(s.rawURL, "${1}"+maskedValue+"@")
+	}
+	masked := false
+	query := reqURL.Query()
+	for _, key := range s.apiKeys {
+		if key.in == APIKeyInQuery && query.Has(key.name) {
+			query.Set(key.name, maskedValue)
+			masked = true
+		}
+	}
+	if masked {
+		reqURL.RawQuery = encodeQuery(query, s.arrayEncoding)
+	}
+	return reqURL.Redacted()
+}
+
+// displayHeader returns a copy of the headers of new requests with the
+// values of credential headers masked.
+func (s *Sling) displayHeader() http.Header {
+	header := s.header.Clone()
+	for name, values := range header {
+		if credentialHeaders[name] || s.isAPIKeyHeader(name) {
+			masked := make([]string, len(values))
+			for i := range masked {
+				masked[i] = maskedValue
+			}
+			header[name] = masked
+		}
+	}
+	return header
+}
+
+// isAPIKeyHeader reports whether name is an API key header of SetAPIKey.
+func (s *Sling) isAPIKeyHeader(name string) bool {
+	for _, key := range s.apiKeys {
+		if key.in == APIKeyInHeader && http.CanonicalHeaderKey(key.name) == name {
+			return true
+		}
+	}
+	return false
+}
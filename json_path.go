@@ -0,0 +1,87 @@
+package sling
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSON decodes the response body as a JSON object.
+func (r *Response) JSON() (map[string]interface{}, error) {
+	data, err := r.bytes()
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GetPath decodes the response body as JSON and returns the value at path.
+// Path segments are separated by dots, numeric segments index arrays and
+// literal dots in keys are escaped with a backslash, e.g.
+//
+//	id, err := resp.GetPath("data.items.0.id")
+//	host, err := resp.GetPath(`hosts.api\.example\.com`)
+//
+// Values are decoded as with encoding/json into an interface{}, so numbers
+// are float64.
+func (r *Response) GetPath(path string) (interface{}, error) {
+	data, err := r.bytes()
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return lookupPath(v, path)
+}
+
+// lookupPath returns the value at the dot separated path within v.
+func lookupPath(v interface{}, path string) (interface{}, error) {
+	if path == "" {
+		return v, nil
+	}
+	for i, segment := range splitJSONPath(path) {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			child, ok := node[segment]
+			if !ok {
+				return nil, fmt.Errorf("sling: path %q: key %q not found", path, segment)
+			}
+			v = child
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("sling: path %q: index %q out of range", path, segment)
+			}
+			v = node[index]
+		default:
+			return nil, fmt.Errorf("sling: path %q: segment %d %q is not an object or array", path, i, segment)
+		}
+	}
+	return v, nil
+}
+
+// splitJSONPath splits path on dots not escaped with a backslash.
+func splitJSONPath(path string) []string {
+	var segments []string
+	var segment strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path) && path[i+1] == '.':
+			segment.WriteByte('.')
+			i++
+		case path[i] == '.':
+			segments = append(segments, segment.String())
+			segment.Reset()
+		default:
+			segment.WriteByte(path[i])
+		}
+	}
+	return append(segments, segment.String())
+}
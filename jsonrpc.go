@@ -0,0 +1,173 @@
+package sling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// protocol decodes the responses of RPC style bodies (see BodyGraphQL and
+// JSONRPC) instead of decodeResponse. Responses are decoded even when
+// successV and failureV are nil, so protocol errors are not lost.
+type protocol interface {
+	decodeResponse(ctx context.Context, resp *Response, isSuccess SuccessDecider, decoder ResponseDecoder, transformers []ResponseTransformer, successV, failureV interface{}) error
+}
+
+const jsonRPCVersion = "2.0"
+
+// JSONRPCError is the error object of a JSON-RPC 2.0 response.
+type JSONRPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *JSONRPCError) Error() string {
+	return fmt.Sprintf("sling: json-rpc error %d: %s", e.Code, e.Message)
+}
+
+// jsonRPCRequest is a JSON-RPC 2.0 request object.
+type jsonRPCRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      int         `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// jsonRPCResponse is a JSON-RPC 2.0 response object.
+type jsonRPCResponse struct {
+	ID     *int            `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *JSONRPCError   `json:"error"`
+}
+
+// JSONRPC sets the Sling's body to a JSON-RPC 2.0 call of method with
+// params (omitted if nil) and decodes responses the JSON-RPC way: the result
+// is decoded into successV and the error object into failureV, or returned
+// as a *JSONRPCError when failureV is nil. Non-2XX responses are decoded
+// into failureV as usual.
+//
+//	var sum int
+//	_, err := api.New().Post("rpc").JSONRPC("add", []int{1, 2}).ReceiveSuccess(&sum)
+//
+// An empty method is ignored.
+func (s *Sling) JSONRPC(method string, params interface{}) *Sling {
+	if method == "" {
+		return s
+	}
+	s.BodyProvider(jsonBodyProvider{payload: jsonRPCRequest{JSONRPC: jsonRPCVersion, ID: 1, Method: method, Params: params}})
+	s.protocol = jsonRPCProtocol{}
+	return s
+}
+
+// jsonRPCProtocol decodes the response of a single JSON-RPC call.
+type jsonRPCProtocol struct{}
+
+var jsonRPCResult = &envelope{dataField: "result"}
+
+func (jsonRPCProtocol) decodeResponse(ctx context.Context, resp *Response, isSuccess SuccessDecider, decoder ResponseDecoder, transformers []ResponseTransformer, successV, failureV interface{}) error {
+	if !isSuccess(resp.Response) {
+		return decodeResponse(ctx, resp, isSuccess, decoder, nil, nil, failureV)
+	}
+	data, err := resp.bytes()
+	if err != nil {
+		return err
+	}
+	var body jsonRPCResponse
+	if err := json.Unmarshal(data, &body); err != nil {
+		return err
+	}
+	if body.Error != nil {
+		if failureV == nil {
+			return body.Error
+		}
+		return envelopeDecoder{decoder: decoder, field: "error"}.Decode(data, failureV)
+	}
+	return jsonRPCResult.decodeResponse(ctx, resp, isSuccess, decoder, transformers, successV, nil)
+}
+
+// JSONRPCCall is a call of a JSON-RPC batch, see JSONRPCBatch.
+type JSONRPCCall struct {
+	Method string
+	Params interface{}
+	// Result points to the value the result of the call is decoded into,
+	// if not nil.
+	Result interface{}
+	// Err is set to the error of the call once the batch response is
+	// decoded, including calls the server did not answer.
+	Err *JSONRPCError
+}
+
+// JSONRPCBatch sets the Sling's body to a JSON-RPC 2.0 batch of calls. Once
+// the response is received, the result of each call is decoded into its
+// Result and its error stored into its Err, matching responses by id:
+//
+//	sum, product := new(int), new(int)
+//	calls := []*sling.JSONRPCCall{
+//		{Method: "add", Params: []int{1, 2}, Result: sum},
+//		{Method: "multiply", Params: []int{3, 4}, Result: product},
+//	}
+//	_, err := api.New().Post("rpc").JSONRPCBatch(calls...).ReceiveSuccess(nil)
+//
+// A batch rejected as a whole returns a *JSONRPCError. Nil calls are
+// ignored.
+func (s *Sling) JSONRPCBatch(calls ...*JSONRPCCall) *Sling {
+	var batch []*JSONRPCCall
+	var reqs []jsonRPCRequest
+	for _, call := range calls {
+		if call != nil && call.Method != "" {
+			batch = append(batch, call)
+			reqs = append(reqs, jsonRPCRequest{JSONRPC: jsonRPCVersion, ID: len(batch), Method: call.Method, Params: call.Params})
+		}
+	}
+	if len(batch) == 0 {
+		return s
+	}
+	s.BodyProvider(jsonBodyProvider{payload: reqs})
+	s.protocol = jsonRPCBatchProtocol{calls: batch}
+	return s
+}
+
+// jsonRPCBatchProtocol decodes the response of a JSON-RPC batch, the calls
+// having ids 1 to len(calls).
+type jsonRPCBatchProtocol struct {
+	calls []*JSONRPCCall
+}
+
+func (p jsonRPCBatchProtocol) decodeResponse(ctx context.Context, resp *Response, isSuccess SuccessDecider, decoder ResponseDecoder, transformers []ResponseTransformer, successV, failureV interface{}) error {
+	if !isSuccess(resp.Response) {
+		return decodeResponse(ctx, resp, isSuccess, decoder, nil, nil, failureV)
+	}
+	data, err := resp.bytes()
+	if err != nil {
+		return err
+	}
+	var bodies []jsonRPCResponse
+	if err := json.Unmarshal(data, &bodies); err != nil {
+		var single jsonRPCResponse
+		if json.Unmarshal(data, &single) == nil && single.Error != nil {
+			return single.Error
+		}
+		return err
+	}
+	answered := make([]bool, len(p.calls))
+	for _, body := range bodies {
+		if body.ID == nil || *body.ID < 1 || *body.ID > len(p.calls) {
+			continue
+		}
+		call := p.calls[*body.ID-1]
+		answered[*body.ID-1] = true
+		call.Err = body.Error
+		if body.Error == nil && call.Result != nil && len(body.Result) > 0 {
+			if err := decoder.Decode(body.Result, call.Result); err != nil {
+				return err
+			}
+		}
+	}
+	for i, call := range p.calls {
+		if !answered[i] {
+			call.Err = &JSONRPCError{Code: -32603, Message: "no response for call " + call.Method}
+		}
+	}
+	return nil
+}
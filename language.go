@@ -0,0 +1,167 @@
+package sling
+
+import (
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ContentLanguage returns the language tags of the Content-Language header
+// of the response, e.g. ["en-US"], or nil if it is missing.
+func (r *Response) ContentLanguage() []string {
+	if r.Response == nil {
+		return nil
+	}
+	var tags []string
+	for _, value := range r.Header.Values("Content-Language") {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// DetectLanguage guesses the ISO 639-1 language of the body of the
+// response, e.g. for content APIs omitting Content-Language. The heuristic
+// recognizes scripts used by a single language (Japanese, Korean, Greek,
+// Thai...) and, for Latin and Cyrillic scripts, counts frequent words of
+// common languages. It returns "" when unsure, e.g. for short or mixed
+// bodies. Prefer ContentLanguage when the server sets it.
+func (r *Response) DetectLanguage() string {
+	data, err := r.bytes()
+	if err != nil {
+		return ""
+	}
+	return detectLanguage(data)
+}
+
+// scriptLanguages maps scripts used by a single language to it.
+var scriptLanguages = []struct {
+	script   *unicode.RangeTable
+	language string
+}{
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Hangul, "ko"},
+	{unicode.Han, "zh"},
+	{unicode.Greek, "el"},
+	{unicode.Hebrew, "he"},
+	{unicode.Arabic, "ar"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+}
+
+// stopwords are frequent words of languages written in Latin or Cyrillic
+// scripts.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "that", "with", "for", "this"},
+	"fr": {"le", "la", "les", "et", "des", "est", "une", "dans", "pour", "que"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "mit", "ein", "eine", "für"},
+	"es": {"el", "los", "las", "y", "es", "una", "por", "para", "con", "que"},
+	"it": {"il", "gli", "della", "che", "è", "una", "per", "non", "sono", "con"},
+	"pt": {"o", "os", "as", "e", "é", "uma", "não", "para", "com", "que"},
+	"nl": {"de", "het", "een", "en", "is", "van", "niet", "met", "voor", "dat"},
+	"vi": {"và", "của", "là", "không", "có", "những", "được", "cho", "với", "này"},
+	"ru": {"и", "в", "не", "на", "что", "это", "с", "как", "по", "для"},
+}
+
+// detectLanguage guesses the language of text, see Response.DetectLanguage.
+func detectLanguage(text []byte) string {
+	if !utf8.Valid(text) {
+		return ""
+	}
+	counts := make(map[string]int)
+	letters := 0
+	for _, r := range string(text) {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, s := range scriptLanguages {
+			if unicode.Is(s.script, r) {
+				counts[s.language]++
+				break
+			}
+		}
+	}
+	// kana and hangul identify Japanese and Korean even mixed with Han
+	if counts["ja"] > 0 && counts["ja"]+counts["zh"] > letters/2 {
+		return "ja"
+	}
+	for _, s := range scriptLanguages {
+		if counts[s.language] > letters/2 {
+			return s.language
+		}
+	}
+
+	words := make(map[string]int)
+	total := 0
+	for _, word := range strings.FieldsFunc(strings.ToLower(string(text)), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		words[word]++
+		total++
+	}
+	best, bestScore, secondScore := "", 0, 0
+	for language, list := range stopwords {
+		score := 0
+		for _, word := range list {
+			score += words[word]
+		}
+		switch {
+		case score > bestScore:
+			best, bestScore, secondScore = language, score, bestScore
+		case score > secondScore:
+			secondScore = score
+		}
+	}
+	// require enough evidence and a clear margin over the runner-up
+	if bestScore < 3 || bestScore*10 < total || bestScore <= secondScore*3/2 {
+		return ""
+	}
+	return best
+}
+
+// LanguageFallback returns a Middleware negotiating the language of
+// localized resources: requests are sent with "Accept-Language:
+// languages[0]" and, when the resource is not available in that language
+// (404 Not Found or 406 Not Acceptable), retried with each following
+// language in turn:
+//
+//	content.Use(sling.LanguageFallback("fr-CA", "fr", "en"))
+//
+// Requests with an Accept-Language header are sent as is, as are requests
+// with bodies that cannot be rewound. The last response is returned.
+func LanguageFallback(languages ...string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, []byte, error) {
+			if len(languages) == 0 || req.Header.Get("Accept-Language") != "" {
+				return next.Do(req)
+			}
+			if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+				return next.Do(req)
+			}
+			var resp *http.Response
+			var rawData []byte
+			var err error
+			for i, language := range languages {
+				attempt := req.Clone(req.Context())
+				if i > 0 && req.GetBody != nil {
+					if attempt.Body, err = req.GetBody(); err != nil {
+						return nil, nil, err
+					}
+				}
+				attempt.Header.Set("Accept-Language", language)
+				if resp != nil {
+					resp.Body.Close()
+				}
+				resp, rawData, err = next.Do(attempt)
+				if err != nil || (resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusNotAcceptable) {
+					break
+				}
+			}
+			return resp, rawData, err
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package sling
+
+import (
+	"net/http"
+)
+
+// Sending a request goes through two layers, so middlewares can target the
+// one they are concerned with:
+//
+//   - the raw layer (RawDoer) sends requests and returns responses with
+//     their body unread: transport concerns such as signing, retries or
+//     header limits. A Doer is the raw layer with the body read, see
+//     FromRawDoer and ToRawDoer.
+//   - the decoding layer (DecodingDoer) decides whether responses succeed
+//     and decodes them into success or failure values, see UseDecoding.
+
+// RawDoer sends requests and returns responses with their body unread, to be
+// read and closed by the caller. It is implemented by HttpWrapper.
+type RawDoer interface {
+	DoRaw(req *http.Request) (*http.Response, error)
+}
+
+// RawDoerFunc adapts a function to a RawDoer.
+type RawDoerFunc func(req *http.Request) (*http.Response, error)
+
+// DoRaw calls f(req).
+func (f RawDoerFunc) DoRaw(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// FromRawDoer adapts raw to a Doer reading and closing response bodies,
+// e.g. to use a RawDoer as the Doer of a Sling (see Doer). Bodies of
+// streamed requests are left unread, see NDJSON.
+func FromRawDoer(raw RawDoer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, []byte, error) {
+		resp, err := raw.DoRaw(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		return readResponse(req, resp, 0, "")
+	})
+}
+
+// ToRawDoer adapts doer to a RawDoer. Response bodies read by doer are
+// replayed from memory.
+func ToRawDoer(doer Doer) RawDoer {
+	if raw, ok := doer.(RawDoer); ok {
+		return raw
+	}
+	return RawDoerFunc(func(req *http.Request) (*http.Response, error) {
+		resp, rawData, err := doer.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if rawData != nil {
+			resp.Body = replayBody(rawData)
+		}
+		return resp, nil
+	})
+}
+
+// DecodingDoer sends requests and decodes their responses into successV or
+// failureV, see Sling.Do. It is implemented by Sling.
+type DecodingDoer interface {
+	DoDecode(req *http.Request, successV, failureV interface{}) (*Response, error)
+}
+
+// DecodingDoerFunc adapts a function to a DecodingDoer.
+type DecodingDoerFunc func(req *http.Request, successV, failureV interface{}) (*Response, error)
+
+// DoDecode calls f(req, successV, failureV).
+func (f DecodingDoerFunc) DoDecode(req *http.Request, successV, failureV interface{}) (*Response, error) {
+	return f(req, successV, failureV)
+}
+
+// DecodingMiddleware wraps the decoding layer, seeing decoded values and
+// decoding errors, e.g. to validate decoded values or log failures.
+type DecodingMiddleware func(next DecodingDoer) DecodingDoer
+
+// DoDecode sends req and decodes its response like Do.
+func (s *Sling) DoDecode(req *http.Request, successV, failureV interface{}) (*Response, error) {
+	return s.Do(req, successV, failureV)
+}
+
+// UseDecoding appends middlewares wrapping the decoding layer of the Sling,
+// around Do and the Receive methods (ReceiveAsync runs them around the
+// decode only). The first middleware is the outermost one. Use Use for
+// middlewares of the raw layer.
+func (s *Sling) UseDecoding(middlewares ...DecodingMiddleware) *Sling {
+	s.checkMutable()
+	for _, mw := range middlewares {
+		if mw != nil {
+			s.decodingLayer = append(s.decodingLayer, mw)
+		}
+	}
+	return s
+}
+
+// decodingDoer returns the decoding layer of the Sling calling decode,
+// wrapped by its decoding middlewares.
+func (s *Sling) decodingDoer(decode DecodingDoerFunc) DecodingDoer {
+	var doer DecodingDoer = decode
+	for i := len(s.decodingLayer) - 1; i >= 0; i-- {
+		doer = s.decodingLayer[i](doer)
+	}
+	return doer
+}
@@ -0,0 +1,67 @@
+package sling
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Lazy is a partially decoded JSON object: only its top level is decoded,
+// each field value is kept as a json.RawMessage and decoded on demand. Use it
+// as successV when only a cursor or a count is needed from a large envelope:
+//
+//	var page sling.Lazy
+//	_, err := api.New().Get("items").ReceiveSuccess(&page)
+//	n, err := page.Count("items")
+//	var next string
+//	err = page.Decode("next", &next)
+//
+// Structs with json.RawMessage fields defer decoding the same way, e.g.
+// struct{ Items json.RawMessage `json:"items"`; Next string `json:"next"` }.
+type Lazy map[string]json.RawMessage
+
+// Has reports whether the object has the field.
+func (l Lazy) Has(field string) bool {
+	_, ok := l[field]
+	return ok
+}
+
+// Decode JSON decodes the field into the value pointed to by v.
+func (l Lazy) Decode(field string, v interface{}) error {
+	raw, ok := l[field]
+	if !ok {
+		return fmt.Errorf("sling: field %q not found", field)
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// Count returns the number of elements of the array field without decoding
+// them.
+func (l Lazy) Count(field string) (int, error) {
+	raw, ok := l[field]
+	if !ok {
+		return 0, fmt.Errorf("sling: field %q not found", field)
+	}
+	return countJSONArray(raw)
+}
+
+// countJSONArray counts the elements of a JSON array by skipping over them.
+func countJSONArray(raw json.RawMessage) (int, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	tok, err := dec.Token()
+	if err != nil {
+		return 0, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return 0, fmt.Errorf("sling: %s is not an array", raw)
+	}
+	n := 0
+	var skip json.RawMessage
+	for dec.More() {
+		if err := dec.Decode(&skip); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	return n, nil
+}
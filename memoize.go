@@ -0,0 +1,196 @@
+package sling
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memoize caches the decoded success values of GET requests for ttl, so
+// identical requests (same URL, headers, tenant, bearer token and successV
+// type) made within ttl are answered in-process without sending them or
+// decoding again. Requests of different tenants (see TenantKey) or bearer
+// tokens (see SetBearerAuthFunc) never share values. It is
+// meant for configuration or metadata lookups called in tight loops and is
+// independent of HTTP caching headers.
+//
+// The cache is shared with Slings derived with New(). Cached values are
+// shallow copies: maps, slices and pointers are shared between callers and
+// must be treated as read-only. Spooled responses and calls with PerRequest
+// options are not memoized. A ttl <= 0 disables memoization. See MemoizeKey
+// to change the key of values and InvalidateMemo to evict them.
+func (s *Sling) Memoize(ttl time.Duration) *Sling {
+	s.checkMutable()
+	if ttl <= 0 {
+		s.memo = nil
+		return s
+	}
+	s.memo = &memoCache{ttl: ttl, memoEntries: &memoEntries{entries: make(map[memoKey]memoEntry)}}
+	return s
+}
+
+// MemoizeKey keys the values memoized by Memoize, which must be called
+// first, with key in place of their URL, headers and principal, e.g. to
+// ignore tracing headers or the order of query parameters:
+//
+//	s.Memoize(time.Minute).MemoizeKey(sling.CacheKey("Accept-Language"))
+//
+// Values are still keyed on the type of successV. The values memoized for
+// the Sling's parent are kept. A nil key restores the default key, and
+// calls without Memoize are recorded as a builder error (see Err).
+func (s *Sling) MemoizeKey(key CacheKeyFunc) *Sling {
+	s.checkMutable()
+	if s.memo == nil {
+		s.addErr("MemoizeKey", "", errors.New("memoization is disabled"))
+		return s
+	}
+	memo := *s.memo
+	memo.keyFunc = key
+	s.memo = &memo
+	return s
+}
+
+// InvalidateMemo evicts the values memoized for requests whose URL path
+// matches pattern (see path.Match), e.g. "/v1/users/*" after updating a
+// user, and returns their number. The values are evicted for all Slings
+// sharing the cache (see Memoize).
+func (s *Sling) InvalidateMemo(pattern string) int {
+	if s.memo == nil {
+		return 0
+	}
+	return s.memo.invalidate(matchPattern(pattern))
+}
+
+// InvalidateMemoURL evicts the values memoized for requests with the
+// scheme, host and path of rawURL, and its query if it has one, and returns
+// their number.
+func (s *Sling) InvalidateMemoURL(rawURL string) int {
+	if s.memo == nil {
+		return 0
+	}
+	return s.memo.invalidate(matchURL(rawURL))
+}
+
+type memoKey struct {
+	url    string
+	header string
+	// custom is the key of MemoizeKey, replacing url, header and
+	// principal.
+	custom string
+	// principal is the tenant and the digest of the bearer token of the
+	// request.
+	principal string
+	typ       reflect.Type
+}
+
+type memoEntry struct {
+	url      *url.URL
+	value    reflect.Value
+	resp     *http.Response
+	rawData  []byte
+	received time.Time
+	expires  time.Time
+}
+
+// memoCache holds memoized success values, see Sling.Memoize.
+type memoCache struct {
+	ttl     time.Duration
+	keyFunc CacheKeyFunc
+	*memoEntries
+}
+
+// memoEntries are the values of a memoCache, shared by the copies of
+// MemoizeKey.
+type memoEntries struct {
+	mu      sync.Mutex
+	entries map[memoKey]memoEntry
+}
+
+// key returns the cache key of req decoding into successV, reporting false
+// for requests which are not memoized.
+func (c *memoCache) key(req *http.Request, successV interface{}) (memoKey, bool) {
+	if req.Method != http.MethodGet || successV == nil {
+		return memoKey{}, false
+	}
+	typ := reflect.TypeOf(successV)
+	if typ.Kind() != reflect.Pointer {
+		return memoKey{}, false
+	}
+	if c.keyFunc != nil {
+		return memoKey{custom: c.keyFunc(req), typ: typ}, true
+	}
+	lines := make([]string, 0, len(req.Header))
+	for k, v := range req.Header {
+		lines = append(lines, k+": "+strings.Join(v, ", "))
+	}
+	sort.Strings(lines)
+	principal := TenantFromContext(req.Context())
+	if token, ok := req.Context().Value(bearerTokenKey{}).(string); ok {
+		digest := sha256.Sum256([]byte(token))
+		principal += "\n" + hex.EncodeToString(digest[:])
+	}
+	return memoKey{url: req.URL.String(), header: strings.Join(lines, "\n"), principal: principal, typ: typ}, true
+}
+
+// load sets the value pointed to by successV to the memoized value of key
+// and returns a copy of the memoized response.
+func (c *memoCache) load(key memoKey, successV interface{}) (*Response, bool) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	reflect.ValueOf(successV).Elem().Set(entry.value)
+	resp := *entry.resp
+	resp.Header = entry.resp.Header.Clone()
+	response := NewResponse(&resp, entry.rawData)
+	response.received = entry.received
+	return response, true
+}
+
+// store memoizes the value pointed to by successV and the response to a
+// request for reqURL as key.
+func (c *memoCache) store(key memoKey, reqURL *url.URL, resp *Response, successV interface{}) {
+	if resp.Spooled() {
+		return
+	}
+	value := reflect.New(key.typ.Elem()).Elem()
+	value.Set(reflect.ValueOf(successV).Elem())
+	httpResp := *resp.Response
+	httpResp.Header = resp.Header.Clone()
+
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = memoEntry{url: reqURL, value: value, resp: &httpResp, rawData: resp.RawData, received: resp.received, expires: now.Add(c.ttl)}
+}
+
+func (c *memoCache) invalidate(match urlMatcher) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	evicted := 0
+	for key, entry := range c.entries {
+		if match(entry.url) {
+			delete(c.entries, key)
+			evicted++
+		}
+	}
+	return evicted
+}
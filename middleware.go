@@ -0,0 +1,111 @@
+package sling
+
+import "net/http"
+
+// DoerFunc adapts a function to a Doer.
+type DoerFunc func(req *http.Request) (*http.Response, []byte, error)
+
+// Do calls f(req).
+func (f DoerFunc) Do(req *http.Request) (*http.Response, []byte, error) {
+	return f(req)
+}
+
+// Middleware wraps a Doer to add behaviour around sending requests, such as
+// signing, logging or validation.
+type Middleware func(next Doer) Doer
+
+// Use appends middlewares wrapping the Sling's Doer (including its retries,
+// see AutoRetry). The first middleware is the outermost one. Middlewares
+// are applied when requests are sent, so they survive later changes of the
+// Doer and are inherited by child Slings.
+func (s *Sling) Use(middlewares ...Middleware) *Sling {
+	s.checkMutable()
+	for _, mw := range middlewares {
+		if mw != nil {
+			s.middlewares = append(s.middlewares, mw)
+		}
+	}
+	return s
+}
+
+// UseIf appends a middleware which only runs for requests matching
+// predicate, e.g. to sign writes only:
+//
+//	s.UseIf(func(req *http.Request) bool { return req.Method != http.MethodGet }, signer)
+//
+// Other requests go straight to the next Doer.
+func (s *Sling) UseIf(predicate func(req *http.Request) bool, mw Middleware) *Sling {
+	if predicate == nil || mw == nil {
+		return s
+	}
+	return s.Use(func(next Doer) Doer {
+		wrapped := mw(next)
+		return DoerFunc(func(req *http.Request) (*http.Response, []byte, error) {
+			if predicate(req) {
+				return wrapped.Do(req)
+			}
+			return next.Do(req)
+		})
+	})
+}
+
+// UseAttempt appends middlewares wrapping each attempt at sending a
+// request, below the retries of AutoRetry and the throttling of Throttle,
+// e.g. to sign every retry afresh (see ProtectReplay). The first middleware
+// is the outermost one. Custom Doers are wrapped as a whole.
+func (s *Sling) UseAttempt(middlewares ...Middleware) *Sling {
+	s.checkMutable()
+	for _, mw := range middlewares {
+		if mw != nil {
+			s.attemptLayer = append(s.attemptLayer, mw)
+		}
+	}
+	return s
+}
+
+// attemptDoer wraps next, sending a single attempt, by the replay protection
+// and the attempt middlewares of the Sling.
+func (s *Sling) attemptDoer(next Doer) Doer {
+	for i := len(s.attemptLayer) - 1; i >= 0; i-- {
+		next = s.attemptLayer[i](next)
+	}
+	if s.replay != nil {
+		next = s.replay.middleware()(next)
+	}
+	return next
+}
+
+// wrapAttempts applies wrap to the Doer sending each attempt at the bottom
+// of doer, rebuilding any RetryDoer or ThrottleDoer around the result (see
+// reconfigureDoer).
+func wrapAttempts(doer Doer, wrap Middleware) Doer {
+	switch d := doer.(type) {
+	case nil:
+		return wrap(defaultClient)
+	case *RetryDoer:
+		retry := *d
+		retry.HTTPClient = wrapAttempts(d.HTTPClient, wrap)
+		return &retry
+	case *ThrottleDoer:
+		throttle := *d
+		throttle.Doer = wrapAttempts(d.Doer, wrap)
+		return &throttle
+	}
+	return wrap(doer)
+}
+
+// doer returns the Sling's Doer wrapped by its attempt middlewares and
+// middlewares, and by the bearer token resolution of SetBearerAuthFunc.
+func (s *Sling) doer() Doer {
+	doer := s.httpClient
+	if s.replay != nil || len(s.attemptLayer) > 0 {
+		doer = wrapAttempts(doer, s.attemptDoer)
+	}
+	for i := len(s.middlewares) - 1; i >= 0; i-- {
+		doer = s.middlewares[i](doer)
+	}
+	if s.bearerToken != nil {
+		doer = &bearerDoer{next: doer, token: s.bearerToken}
+	}
+	return doer
+}
@@ -0,0 +1,336 @@
+package sling
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	goquery "github.com/google/go-querystring/query"
+)
+
+const (
+	multipartContentType = "multipart/form-data"
+	octetStreamType      = "application/octet-stream"
+)
+
+// FileField is a file part of a multipart form. Use it as a field of a url
+// tagged struct passed to BodyForm to set the filename and content type of
+// the part, e.g.
+//
+//	type Upload struct {
+//		Title  string          `url:"title"`
+//		Avatar sling.FileField `url:"avatar"`
+//	}
+type FileField struct {
+	// Filename of the part, defaults to the field name.
+	Filename string
+	// ContentType of the part, defaults to application/octet-stream.
+	ContentType string
+	// Reader provides the file content. Fields with a nil Reader are
+	// omitted.
+	Reader io.Reader
+}
+
+// EncodeValues implements go-querystring's Encoder so that FileFields are
+// not encoded as form values.
+func (f FileField) EncodeValues(key string, v *url.Values) error {
+	return nil
+}
+
+// multipartPart is a form value or file part of a multipart body.
+type multipartPart struct {
+	name        string
+	value       string
+	filename    string
+	contentType string
+	// reader is the file content, nil for form values.
+	reader io.Reader
+	// path is the file opened as content when set.
+	path string
+}
+
+// MultipartBody is a multipart/form-data BodyProvider built from fields and
+// files, see Sling.BodyMultipart. Files are streamed when requests are sent
+// rather than read into memory.
+type MultipartBody struct {
+	boundary string
+	parts    []multipartPart
+}
+
+// NewMultipartBody returns an empty MultipartBody.
+func NewMultipartBody() *MultipartBody {
+	return &MultipartBody{boundary: randomBoundary()}
+}
+
+// AddField adds a form field.
+func (b *MultipartBody) AddField(name, value string) *MultipartBody {
+	b.parts = append(b.parts, multipartPart{name: name, value: value})
+	return b
+}
+
+// AddFile adds a file part with the content of the file at path, opened
+// when the body is sent. The filename is the base name of path and the
+// content type is detected from its extension.
+func (b *MultipartBody) AddFile(name, path string) *MultipartBody {
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	b.parts = append(b.parts, multipartPart{name: name, filename: filepath.Base(path), contentType: contentType, path: path})
+	return b
+}
+
+// AddReader adds a file part with the given filename and content type
+// (application/octet-stream if empty) reading its content from r. Readers
+// implementing io.Seeker are rewound, others can only be sent once.
+func (b *MultipartBody) AddReader(name, filename, contentType string, r io.Reader) *MultipartBody {
+	b.parts = append(b.parts, multipartPart{name: name, filename: filename, contentType: contentType, reader: r})
+	return b
+}
+
+// ContentType returns multipart/form-data with the body boundary.
+func (b *MultipartBody) ContentType() string {
+	return multipartContentType + "; boundary=" + b.boundary
+}
+
+// Body returns a reader streaming the multipart body. Missing files are
+// reported before anything is sent. Bodies whose files are all added by
+// path can be recreated for retries and redirects.
+func (b *MultipartBody) Body() (io.Reader, error) {
+	parts := append([]multipartPart(nil), b.parts...)
+	reopenable := true
+	for _, p := range parts {
+		if p.path != "" {
+			if _, err := os.Stat(p.path); err != nil {
+				return nil, err
+			}
+		} else if p.reader != nil {
+			if _, ok := p.reader.(io.Seeker); !ok {
+				reopenable = false
+			}
+		}
+	}
+	body := pipeMultipart(b.boundary, parts)
+	if !reopenable {
+		return body, nil
+	}
+	return &rewindableBody{ReadCloser: body, getBody: func() (io.ReadCloser, error) {
+		return pipeMultipart(b.boundary, parts), nil
+	}}, nil
+}
+
+// rewindableBody is a request body which can be recreated (see
+// http.Request.GetBody).
+type rewindableBody struct {
+	io.ReadCloser
+	getBody func() (io.ReadCloser, error)
+}
+
+// multipartFormBodyProvider encodes a url tagged struct value with file
+// fields as a multipart/form-data Body for requests.
+type multipartFormBodyProvider struct {
+	payload  interface{}
+	boundary string
+}
+
+func (p multipartFormBodyProvider) ContentType() string {
+	return multipartContentType + "; boundary=" + p.boundary
+}
+
+func (p multipartFormBodyProvider) Body() (io.Reader, error) {
+	values, err := goquery.Values(p.payload)
+	if err != nil {
+		return nil, err
+	}
+	files := formFileParts(p.payload)
+	fileNames := make(map[string]bool, len(files))
+	for _, f := range files {
+		fileNames[f.name] = true
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		// file fields which go-querystring descends into, such as *os.File,
+		// may still produce nested keys
+		name, _, _ := strings.Cut(key, "[")
+		if !fileNames[name] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	var parts []multipartPart
+	for _, key := range keys {
+		for _, value := range values[key] {
+			parts = append(parts, multipartPart{name: key, value: value})
+		}
+	}
+	return pipeMultipart(p.boundary, append(parts, files...)), nil
+}
+
+var (
+	fileFieldType = reflect.TypeOf(FileField{})
+	readerType    = reflect.TypeOf((*io.Reader)(nil)).Elem()
+)
+
+// hasFileFields reports whether the url tagged struct (or pointer to struct)
+// payload has fields of type FileField or implementing io.Reader.
+func hasFileFields(payload interface{}) bool {
+	t := reflect.TypeOf(payload)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath == "" && sf.Tag.Get("url") != "-" && isFileFieldType(sf.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+func isFileFieldType(t reflect.Type) bool {
+	return t == fileFieldType || t.Implements(readerType)
+}
+
+// formFileParts returns the non-nil file fields of payload as parts, named
+// after their url tag. *os.File fields use the base name of the file as
+// filename.
+func formFileParts(payload interface{}) []multipartPart {
+	v := reflect.ValueOf(payload)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	t := v.Type()
+	var parts []multipartPart
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("url")
+		if sf.PkgPath != "" || tag == "-" || !isFileFieldType(sf.Type) {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+		part := multipartPart{name: name, filename: name}
+		fv := v.Field(i)
+		switch {
+		case sf.Type == fileFieldType:
+			f := fv.Interface().(FileField)
+			if f.Reader == nil {
+				continue
+			}
+			part.reader, part.contentType = f.Reader, f.ContentType
+			if f.Filename != "" {
+				part.filename = f.Filename
+			}
+		default:
+			if (fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface) && fv.IsNil() {
+				continue
+			}
+			part.reader = fv.Interface().(io.Reader)
+			if f, ok := part.reader.(*os.File); ok {
+				part.filename = filepath.Base(f.Name())
+			}
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// pipeMultipart returns a reader streaming the multipart body of parts as it
+// is written, so file contents are not buffered in memory.
+func pipeMultipart(boundary string, parts []multipartPart) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeMultipart(pw, boundary, parts))
+	}()
+	return pr
+}
+
+// closeBody closes body after its request failed to be built with err,
+// stopping the goroutine writing piped bodies (see pipeMultipart).
+func closeBody(body io.Reader, err error) {
+	switch b := body.(type) {
+	case *rewindableBody:
+		closeBody(b.ReadCloser, err)
+	case *io.PipeReader:
+		b.CloseWithError(err)
+	case io.Closer:
+		b.Close()
+	}
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// writeMultipart writes parts to w as a multipart body. File readers which
+// implement io.Seeker are rewound first, so the body can be written again
+// for retried or repeated requests.
+func writeMultipart(w io.Writer, boundary string, parts []multipartPart) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(boundary); err != nil {
+		return err
+	}
+	for _, p := range parts {
+		if p.reader == nil && p.path == "" {
+			if err := mw.WriteField(p.name, p.value); err != nil {
+				return err
+			}
+			continue
+		}
+		contentType := p.contentType
+		if contentType == "" {
+			contentType = octetStreamType
+		}
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(p.name), quoteEscaper.Replace(p.filename)))
+		h.Set(hdrContentTypeKey, contentType)
+		pw, err := mw.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if err := copyPart(pw, p); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// copyPart writes the file content of p to w.
+func copyPart(w io.Writer, p multipartPart) error {
+	r := p.reader
+	if p.path != "" {
+		f, err := os.Open(p.path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	} else if seeker, ok := r.(io.Seeker); ok {
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	_, err := io.Copy(w, r)
+	return err
+}
+
+// randomBoundary returns a random multipart boundary.
+func randomBoundary() string {
+	return multipart.NewWriter(io.Discard).Boundary()
+}
@@ -0,0 +1,109 @@
+package sling
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// NDJSON is a success value streaming newline delimited JSON
+// (application/x-ndjson) responses: each record is decoded into a T and
+// passed to the function as soon as it is read, so large exports are not
+// buffered into RawData.
+//
+//	err := sling.NDJSON[Event](func(e Event) error {
+//		return store(e)
+//	})
+//	resp, err := api.New().Get("events/export").ReceiveSuccess(err)
+//
+// Decoding stops at the first error returned by the function, which is then
+// returned by Receive. The Body of the Response is consumed by the stream:
+// ResetBody replays it only if it was read to its end and is at most 1 MiB. Failure responses are buffered as usual. Doers other
+// than the default HttpWrapper (and the Doers wrapping it) may still buffer
+// the body before it is streamed.
+type NDJSON[T any] func(record T) error
+
+// streamReceiver is implemented by success values reading the response body
+// as it is received.
+type streamReceiver interface {
+	receive(ctx context.Context, r io.Reader) error
+}
+
+func (fn NDJSON[T]) receive(ctx context.Context, r io.Reader) error {
+	dec := json.NewDecoder(contextReader{ctx: ctx, r: r})
+	for {
+		var record T
+		if err := dec.Decode(&record); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+type streamKey struct{}
+
+// withStreaming returns a copy of ctx asking the HttpWrapper not to buffer
+// the response body.
+func withStreaming(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamKey{}, true)
+}
+
+func isStreaming(ctx context.Context) bool {
+	streaming, _ := ctx.Value(streamKey{}).(bool)
+	return streaming
+}
+
+// streamReplayLimit bounds the size of streamed bodies kept as they are read
+// so that Response.ResetBody can replay them.
+const streamReplayLimit = 1 << 20
+
+// streamBody is a response body left unread for a streamReceiver. The caller
+// of Do closes it. Bodies of at most streamReplayLimit bytes are kept while
+// they are read.
+type streamBody struct {
+	io.ReadCloser
+	kept     []byte
+	overflow bool
+	eof      bool
+}
+
+func (b *streamBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if !b.overflow {
+		if len(b.kept)+n > streamReplayLimit {
+			b.overflow, b.kept = true, nil
+		} else {
+			b.kept = append(b.kept, p[:n]...)
+		}
+	}
+	if err == io.EOF {
+		b.eof = true
+	}
+	return n, err
+}
+
+// replayable reports whether the whole body was read and kept.
+func (b *streamBody) replayable() bool {
+	return b.eof && !b.overflow
+}
+
+// buffer reads a streamed body into RawData.
+func (r *Response) buffer() error {
+	body := r.stream
+	if body == nil {
+		return nil
+	}
+	r.stream = nil
+	defer body.Close()
+	rawData, err := io.ReadAll(body)
+	if err != nil {
+		return classifyNetError(err)
+	}
+	r.RawData = rawData
+	r.ResetBody()
+	return nil
+}
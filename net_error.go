@@ -0,0 +1,156 @@
+package sling
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// NetErrorClass classifies low-level transport failures.
+type NetErrorClass int
+
+const (
+	// NetErrorUnknown is any failure not covered by the other classes.
+	NetErrorUnknown NetErrorClass = iota
+	// NetErrorConnReset is a connection reset by the peer (ECONNRESET).
+	NetErrorConnReset
+	// NetErrorConnRefused is a refused connection (ECONNREFUSED).
+	NetErrorConnRefused
+	// NetErrorUnexpectedEOF is a connection closed before the response was
+	// complete.
+	NetErrorUnexpectedEOF
+	// NetErrorDNSNotFound is a host which does not exist (NXDOMAIN).
+	NetErrorDNSNotFound
+	// NetErrorDNSTimeout is a DNS lookup which timed out.
+	NetErrorDNSTimeout
+	// NetErrorDNS is any other DNS lookup failure.
+	NetErrorDNS
+	// NetErrorTimeout is a dial, TLS handshake or read timeout.
+	NetErrorTimeout
+	// NetErrorTLSVerification is a failed server certificate verification.
+	NetErrorTLSVerification
+)
+
+var netErrorClassNames = map[NetErrorClass]string{
+	NetErrorUnknown:         "unknown",
+	NetErrorConnReset:       "connection reset",
+	NetErrorConnRefused:     "connection refused",
+	NetErrorUnexpectedEOF:   "unexpected EOF",
+	NetErrorDNSNotFound:     "DNS not found",
+	NetErrorDNSTimeout:      "DNS timeout",
+	NetErrorDNS:             "DNS failure",
+	NetErrorTimeout:         "timeout",
+	NetErrorTLSVerification: "TLS verification",
+}
+
+func (c NetErrorClass) String() string {
+	if name, ok := netErrorClassNames[c]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// NetError is a transport failure classified by its NetErrorClass. The
+// original error (usually a *url.Error) is available through errors.As, as
+// type assertions such as err.(*url.Error) don't see through the NetError.
+type NetError struct {
+	Class NetErrorClass
+	Err   error
+}
+
+func (e *NetError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NetError) Unwrap() error {
+	return e.Err
+}
+
+// ClassifyNetError returns the NetErrorClass of err.
+func ClassifyNetError(err error) NetErrorClass {
+	if err == nil {
+		return NetErrorUnknown
+	}
+	var netErr *NetError
+	if errors.As(err, &netErr) {
+		return netErr.Class
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		switch {
+		case dnsErr.IsNotFound:
+			return NetErrorDNSNotFound
+		case dnsErr.IsTimeout:
+			return NetErrorDNSTimeout
+		}
+		return NetErrorDNS
+	}
+
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		hostname         x509.HostnameError
+		invalidCert      x509.CertificateInvalidError
+		verification     *tls.CertificateVerificationError
+	)
+	if errors.As(err, &unknownAuthority) || errors.As(err, &hostname) ||
+		errors.As(err, &invalidCert) || errors.As(err, &verification) {
+		return NetErrorTLSVerification
+	}
+
+	switch {
+	case errors.Is(err, syscall.ECONNRESET):
+		return NetErrorConnReset
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return NetErrorConnRefused
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return NetErrorUnexpectedEOF
+	case errors.Is(err, context.Canceled):
+		return NetErrorUnknown
+	}
+
+	var timeout interface{ Timeout() bool }
+	if errors.As(err, &timeout) && timeout.Timeout() {
+		return NetErrorTimeout
+	}
+	return NetErrorUnknown
+}
+
+// classifyNetError wraps err in a *NetError unless its class is unknown.
+func classifyNetError(err error) error {
+	class := ClassifyNetError(err)
+	if class == NetErrorUnknown {
+		return err
+	}
+	return &NetError{Class: class, Err: err}
+}
+
+// NetErrorRetryPolicy returns a CheckRetry which handles responses like
+// DefaultRetryPolicy but only retries transport errors of the given classes.
+// For example, retry resets and unexpected EOFs but never certificate
+// failures:
+//
+//	sling.New().AutoRetry(sling.WithRetryPolicy(
+//		sling.NetErrorRetryPolicy(sling.NetErrorConnReset, sling.NetErrorUnexpectedEOF),
+//	))
+func NetErrorRetryPolicy(classes ...NetErrorClass) CheckRetry {
+	retryable := make(map[NetErrorClass]bool, len(classes))
+	for _, class := range classes {
+		retryable[class] = true
+	}
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		// do not retry on context.Canceled or context.DeadlineExceeded
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		if err != nil {
+			return retryable[ClassifyNetError(err)], nil
+		}
+		return DefaultRetryPolicy(ctx, resp, nil)
+	}
+}
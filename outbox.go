@@ -0,0 +1,342 @@
+package sling
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// QueuedError is returned for requests which failed with a transport error
+// and were queued in an Outbox for replay.
+type QueuedError struct {
+	Err error
+}
+
+func (e *QueuedError) Error() string {
+	return "sling: request queued for replay: " + e.Err.Error()
+}
+
+func (e *QueuedError) Unwrap() error {
+	return e.Err
+}
+
+// Outbox persists requests which failed because the server could not be
+// reached and replays them once it can, for telemetry or webhook senders
+// which must not lose requests while offline:
+//
+//	outbox, err := sling.NewOutbox("/var/lib/app/outbox", sling.WithOutboxLimit(1000), sling.WithOutboxTTL(24*time.Hour))
+//	api := sling.New().Base("https://collector.example.com/").Use(outbox.Middleware())
+//	go outbox.ReplayEvery(ctx, api, time.Minute)
+//
+// Each request is stored as a JSON file in the outbox directory, without
+// its credentials.
+type Outbox struct {
+	dir   string
+	limit int
+	ttl   time.Duration
+
+	mu  sync.Mutex
+	seq int
+	now func() time.Time
+	// replayMu serializes replays, which send without holding mu
+	replayMu sync.Mutex
+}
+
+// OutboxOption configures an Outbox.
+type OutboxOption func(o *Outbox)
+
+// WithOutboxLimit bounds the number of queued requests, dropping the oldest
+// ones when full. The default is 1000.
+func WithOutboxLimit(n int) OutboxOption {
+	return func(o *Outbox) {
+		if n > 0 {
+			o.limit = n
+		}
+	}
+}
+
+// WithOutboxTTL drops queued requests older than ttl instead of replaying
+// them. The default is to keep them until replayed.
+func WithOutboxTTL(ttl time.Duration) OutboxOption {
+	return func(o *Outbox) {
+		o.ttl = ttl
+	}
+}
+
+// NewOutbox returns an Outbox storing requests in dir, which is created if
+// needed. Requests queued by a previous process are kept.
+func NewOutbox(dir string, opts ...OutboxOption) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	o := &Outbox{dir: dir, limit: 1000, now: time.Now}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o, nil
+}
+
+// outboxEntry is the stored form of a queued request.
+type outboxEntry struct {
+	Method   string      `json:"method"`
+	URL      string      `json:"url"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body,omitempty"`
+	Enqueued time.Time   `json:"enqueued"`
+}
+
+// outboxReplayKey is the context key of the Outbox replaying a request, which
+// its Middleware doesn't queue again.
+type outboxReplayKey struct{}
+
+// outboxClasses are the transport failures worth replaying.
+var outboxClasses = map[NetErrorClass]bool{
+	NetErrorConnReset:     true,
+	NetErrorConnRefused:   true,
+	NetErrorUnexpectedEOF: true,
+	NetErrorDNSNotFound:   true,
+	NetErrorDNSTimeout:    true,
+	NetErrorDNS:           true,
+	NetErrorTimeout:       true,
+}
+
+// Middleware returns a Middleware queuing requests which fail because the
+// server cannot be reached (connection refused or reset, DNS failures,
+// timeouts...) and returning a *QueuedError for them. Requests canceled by
+// their context or replayed by the Outbox are not queued. Request bodies are
+// buffered so that they can be stored. Credentials aren't stored: the
+// credential headers (Authorization, Proxy-Authorization, Cookie, X-Api-Key
+// and X-Auth-Token), the API keys of SetAPIKey and the user info of the URL,
+// see Replay.
+func (o *Outbox) Middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, []byte, error) {
+			if req.Context().Value(outboxReplayKey{}) == o {
+				return next.Do(req)
+			}
+			var body []byte
+			if req.Body != nil && req.Body != http.NoBody {
+				var err error
+				if body, err = io.ReadAll(req.Body); err != nil {
+					return nil, nil, err
+				}
+				req.Body.Close()
+				req = req.Clone(req.Context())
+				req.Body = io.NopCloser(bytes.NewReader(body))
+				req.GetBody = func() (io.ReadCloser, error) {
+					return io.NopCloser(bytes.NewReader(body)), nil
+				}
+			}
+			resp, rawData, err := next.Do(req)
+			if err == nil || req.Context().Err() != nil || !outboxClasses[ClassifyNetError(err)] {
+				return resp, rawData, err
+			}
+			keys, _ := req.Context().Value(apiKeysKey{}).([]apiKey)
+			header, reqURL := stripCredentials(req, keys)
+			entry := outboxEntry{Method: req.Method, URL: reqURL.String(), Header: header, Body: body}
+			if qErr := o.enqueue(entry); qErr != nil {
+				return resp, rawData, err
+			}
+			AddSpanEvent(req.Context(), SpanEventQueued, attribute.String("sling.outbox.error", err.Error()))
+			return resp, rawData, &QueuedError{Err: err}
+		})
+	}
+}
+
+// apiKeysKey is the context key of the API keys of the Sling sending a
+// request, see SetAPIKey.
+type apiKeysKey struct{}
+
+// stripCredentials returns the headers and URL of req without credentials:
+// credential and API key headers, API key query parameters and user info.
+func stripCredentials(req *http.Request, keys []apiKey) (http.Header, *url.URL) {
+	header := req.Header.Clone()
+	for name := range credentialHeaders {
+		header.Del(name)
+	}
+	reqURL := *req.URL
+	reqURL.User = nil
+	query := reqURL.Query()
+	for _, key := range keys {
+		if key.in == APIKeyInHeader {
+			header.Del(key.name)
+		} else if query.Has(key.name) {
+			query.Del(key.name)
+			reqURL.RawQuery = query.Encode()
+		}
+	}
+	return header, &reqURL
+}
+
+// setCredentials sets the credentials left out by stripCredentials on req
+// from the headers, cookies, API keys and URL of s.
+func (s *Sling) setCredentials(req *http.Request) {
+	for key, values := range s.header {
+		if credentialHeaders[key] || s.isAPIKeyHeader(key) {
+			req.Header[key] = values
+		}
+	}
+	for _, cookie := range s.cookies {
+		req.AddCookie(cookie)
+	}
+	sURL, err := s.URL()
+	if err != nil {
+		return
+	}
+	if sURL.User != nil && sURL.Host == req.URL.Host {
+		req.URL.User = sURL.User
+	}
+	query, keys := req.URL.Query(), sURL.Query()
+	for _, key := range s.apiKeys {
+		if key.in == APIKeyInQuery && keys.Has(key.name) {
+			query[key.name] = keys[key.name]
+			req.URL.RawQuery = query.Encode()
+		}
+	}
+}
+
+// Len returns the number of queued requests.
+func (o *Outbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	names, _ := o.names()
+	return len(names)
+}
+
+func (o *Outbox) enqueue(entry outboxEntry) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	entry.Enqueued = o.now()
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	o.seq++
+	name := fmt.Sprintf("%020d-%06d.json", entry.Enqueued.UnixNano(), o.seq)
+	if err := os.WriteFile(filepath.Join(o.dir, name), b, 0o600); err != nil {
+		return err
+	}
+	names, err := o.names()
+	if err != nil {
+		return err
+	}
+	for len(names) > o.limit {
+		os.Remove(filepath.Join(o.dir, names[0]))
+		names = names[1:]
+	}
+	return nil
+}
+
+// names returns the queued entry files, oldest first.
+func (o *Outbox) names() ([]string, error) {
+	files, err := os.ReadDir(o.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, f := range files {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), ".json") {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Replay sends the queued requests, oldest first, through the Doer and
+// middlewares of s, which sets the credentials left out of the outbox from
+// its headers, cookies, API keys and URL user info. Requests are removed once a response
+// is received, whatever its status, and expired requests are dropped.
+// Replay stops at the first transport error, leaving the remaining requests
+// queued, and returns the number of requests sent. Requests failing while
+// replays are sent are queued as usual.
+func (o *Outbox) Replay(ctx context.Context, s *Sling) (int, error) {
+	if s == nil {
+		return 0, errors.New("sling: Outbox.Replay without Sling")
+	}
+	o.replayMu.Lock()
+	defer o.replayMu.Unlock()
+	o.mu.Lock()
+	names, err := o.names()
+	o.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	ctx = context.WithValue(ctx, outboxReplayKey{}, o)
+	doer := s.doer()
+	sent := 0
+	for _, name := range names {
+		path := filepath.Join(o.dir, name)
+		req, ok := o.load(ctx, path)
+		if !ok {
+			continue
+		}
+		s.setCredentials(req)
+		resp, _, err := doer.Do(req)
+		if err != nil {
+			return sent, err
+		}
+		resp.Body.Close()
+		o.remove(path)
+		sent++
+	}
+	return sent, nil
+}
+
+// load returns the request queued in path, removing the entries which are
+// expired or can't be read.
+func (o *Outbox) load(ctx context.Context, path string) (*http.Request, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var entry outboxEntry
+	b, err := os.ReadFile(path)
+	if err == nil {
+		err = json.Unmarshal(b, &entry)
+	}
+	if err != nil || (o.ttl > 0 && o.now().Sub(entry.Enqueued) > o.ttl) {
+		os.Remove(path)
+		return nil, false
+	}
+	req, err := http.NewRequestWithContext(ctx, entry.Method, entry.URL, bytes.NewReader(entry.Body))
+	if err != nil {
+		os.Remove(path)
+		return nil, false
+	}
+	if entry.Header != nil {
+		req.Header = entry.Header
+	}
+	return req, true
+}
+
+func (o *Outbox) remove(path string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	os.Remove(path)
+}
+
+// ReplayEvery calls Replay with s every interval until ctx is done.
+func (o *Outbox) ReplayEvery(ctx context.Context, s *Sling, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.Replay(ctx, s)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package sling
+
+import (
+	"context"
+	"time"
+)
+
+// PerRequest overrides the Sling's settings for a single Receive or Do call
+// without mutating or cloning the Sling. Zero valued fields keep the Sling's
+// settings. When several are given, later non-zero fields win.
+//
+//	resp, err := api.Receive(user, apiErr, sling.PerRequest{Timeout: time.Second})
+type PerRequest struct {
+	// Timeout bounds the call, including retries.
+	Timeout time.Duration
+	// RetryPolicy replaces the CheckRetry of any RetryDoer handling the call
+	// (see AutoRetry).
+	RetryPolicy CheckRetry
+	// Decoder replaces the response decoder.
+	Decoder ResponseDecoder
+	// SuccessDecider replaces the success decider.
+	SuccessDecider SuccessDecider
+}
+
+// mergePerRequest merges opts into a single PerRequest, later non-zero
+// fields winning.
+func mergePerRequest(opts []PerRequest) PerRequest {
+	var merged PerRequest
+	for _, opt := range opts {
+		if opt.Timeout != 0 {
+			merged.Timeout = opt.Timeout
+		}
+		if opt.RetryPolicy != nil {
+			merged.RetryPolicy = opt.RetryPolicy
+		}
+		if opt.Decoder != nil {
+			merged.Decoder = opt.Decoder
+		}
+		if opt.SuccessDecider != nil {
+			merged.SuccessDecider = opt.SuccessDecider
+		}
+	}
+	return merged
+}
+
+type retryPolicyKey struct{}
+
+// contextWithRetryPolicy returns a copy of ctx carrying a CheckRetry which
+// overrides the policy of RetryDoers.
+func contextWithRetryPolicy(ctx context.Context, policy CheckRetry) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// retryPolicyFromContext returns the CheckRetry carried by ctx, if any.
+func retryPolicyFromContext(ctx context.Context) (CheckRetry, bool) {
+	policy, ok := ctx.Value(retryPolicyKey{}).(CheckRetry)
+	return policy, ok
+}
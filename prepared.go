@@ -0,0 +1,208 @@
+package sling
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Bindings are the values of the placeholders of a PreparedRequest.
+type Bindings map[string]interface{}
+
+// PreparedRequest is a request template built once from a Sling and sent
+// many times with different Bindings, cheaply and concurrently, for high
+// QPS templated calls:
+//
+//	getOrder, err := api.New().Get("orders/{id}").QueryParam("expand", "{expand}").Prepare()
+//	resp, err := getOrder.Receive(ctx, sling.Bindings{"id": 42, "expand": "items"}, &order, nil)
+//
+// Placeholders are written {name} in the path and query values, and as
+// the JSON string "{name}" in JSON bodies. A doubled opening brace is a
+// literal brace, e.g. {{name} for the text {name}. Path and query bindings
+// are formatted with fmt.Sprint and escaped, path bindings as a single
+// segment (see PathSegments), body bindings are JSON encoded, so numbers
+// stay numbers.
+type PreparedRequest struct {
+	sling *Sling
+	url   template
+	body  template
+	// hasQuery is true for URLs with a query.
+	hasQuery bool
+	// hasBody is false for requests without a body.
+	hasBody bool
+}
+
+// template is a string split around its placeholders, parts has one more
+// element than names.
+type template struct {
+	parts []string
+	names []string
+	// offsets are the positions of the placeholders in the compiled string.
+	offsets []int
+	// query marks placeholders of the query.
+	query []bool
+}
+
+// Prepare returns a PreparedRequest sending the requests of a copy of s,
+// with the placeholders of its URL and body bound when sent. Bodies are
+// encoded once, so BodyFunc bodies cannot be prepared.
+func (s *Sling) Prepare() (*PreparedRequest, error) {
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	reqURL, err := s.requestURL(false)
+	if err != nil {
+		return nil, err
+	}
+	rawURL := strings.NewReplacer("%7B", "{", "%7D", "}").Replace(reqURL.String())
+	p := &PreparedRequest{sling: s.New()}
+	if p.url, err = compileTemplate(rawURL, "{", "}", "%7B"); err != nil {
+		return nil, err
+	}
+	for i, part := range p.url.parts {
+		// braces outside placeholders are literal
+		p.url.parts[i] = strings.ReplaceAll(part, "}", "%7D")
+	}
+	queryStart := strings.IndexByte(rawURL, '?')
+	p.hasQuery = queryStart >= 0
+	for _, offset := range p.url.offsets {
+		p.url.query = append(p.url.query, queryStart >= 0 && offset > queryStart)
+	}
+	if s.bodyProvider == nil {
+		return p, nil
+	}
+	var body io.Reader
+	switch provider := s.bodyProvider.(type) {
+	case funcBodyProvider:
+		return nil, errors.New("sling: Prepare: BodyFunc bodies cannot be prepared")
+	case formBodyProvider:
+		provider.timeFormat = s.timeFormat
+		body, err = provider.Body()
+	default:
+		body, err = provider.Body()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		payload, err := io.ReadAll(body)
+		if closer, ok := body.(io.Closer); ok {
+			closer.Close()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if p.body, err = compileTemplate(string(payload), `"{`, `}"`, `"{`); err != nil {
+			return nil, err
+		}
+		p.hasBody = true
+	}
+	return p, nil
+}
+
+// compileTemplate splits s around the placeholders delimited by open and
+// close. An open delimiter followed by "{" is written as literal instead.
+func compileTemplate(s, open, close, literal string) (template, error) {
+	var t template
+	var part strings.Builder
+	offset := 0
+	for {
+		start := strings.Index(s, open)
+		if start < 0 {
+			part.WriteString(s)
+			t.parts = append(t.parts, part.String())
+			return t, nil
+		}
+		part.WriteString(s[:start])
+		if strings.HasPrefix(s[start+len(open):], "{") {
+			part.WriteString(literal)
+			s, offset = s[start+len(open)+1:], offset+start+len(open)+1
+			continue
+		}
+		end := strings.Index(s[start+len(open):], close)
+		if end < 0 {
+			return t, fmt.Errorf("sling: Prepare: unclosed placeholder in %q", s)
+		}
+		t.parts = append(t.parts, part.String())
+		part.Reset()
+		t.names = append(t.names, s[start+len(open):start+len(open)+end])
+		t.offsets = append(t.offsets, offset+start)
+		skip := start + len(open) + end + len(close)
+		s, offset = s[skip:], offset+skip
+	}
+}
+
+// expand writes the template with its placeholders replaced by the values
+// render returns for their bindings.
+func (t template) expand(b *bytes.Buffer, bindings Bindings, render func(i int, value interface{}) (string, error)) error {
+	for i, name := range t.names {
+		b.WriteString(t.parts[i])
+		value, ok := bindings[name]
+		if !ok {
+			return fmt.Errorf("sling: prepared request: missing binding %q", name)
+		}
+		rendered, err := render(i, value)
+		if err != nil {
+			return err
+		}
+		b.WriteString(rendered)
+	}
+	b.WriteString(t.parts[len(t.parts)-1])
+	return nil
+}
+
+// Request returns a new http.Request with the placeholders replaced by
+// bindings, sent with ctx (the context of the Sling if nil).
+func (p *PreparedRequest) Request(ctx context.Context, bindings Bindings) (*http.Request, error) {
+	s := p.sling
+	if ctx == nil {
+		ctx = s.Context()
+	}
+	var rawURL bytes.Buffer
+	err := p.url.expand(&rawURL, bindings, func(i int, value interface{}) (string, error) {
+		if p.url.query[i] {
+			return url.QueryEscape(fmt.Sprint(value)), nil
+		}
+		return escapePathSegment(fmt.Sprint(value)), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if s.cacheBustParam != "" {
+		if p.hasQuery {
+			rawURL.WriteByte('&')
+		} else {
+			rawURL.WriteByte('?')
+		}
+		rawURL.WriteString(url.QueryEscape(s.cacheBustParam) + "=" + cacheBustNonce())
+	}
+	var body io.Reader
+	if p.hasBody {
+		payload := &bytes.Buffer{}
+		err := p.body.expand(payload, bindings, func(_ int, value interface{}) (string, error) {
+			encoded, err := json.Marshal(value)
+			return string(encoded), err
+		})
+		if err != nil {
+			return nil, err
+		}
+		body = payload
+	}
+	return s.newRequest(ctx, rawURL.String(), body, "")
+}
+
+// Receive sends the request bound with bindings like Sling.Receive,
+// decoding the response into successV or failureV.
+func (p *PreparedRequest) Receive(ctx context.Context, bindings Bindings, successV, failureV interface{}) (*Response, error) {
+	req, err := p.Request(ctx, bindings)
+	if err != nil {
+		return nil, err
+	}
+	return p.sling.Do(req, successV, failureV)
+}
@@ -0,0 +1,47 @@
+package sling
+
+import (
+	"context"
+	"net/http"
+	"runtime/pprof"
+	"sort"
+)
+
+// WithProfiling sets pprof labels applied to the goroutine sending a request
+// for the duration of Do (and Receive), and to the request context, so that
+// CPU and heap profiles of high QPS clients attribute their cost to
+// endpoints:
+//
+//	s.Get("search").WithProfiling(map[string]string{"endpoint": "search"})
+//
+// Labels are added to those of the calling goroutine's context. Empty labels
+// disable profiling labels.
+func (s *Sling) WithProfiling(labels map[string]string) *Sling {
+	s.checkMutable()
+	if len(labels) == 0 {
+		s.profileLabels = nil
+		return s
+	}
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	s.profileLabels = make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		s.profileLabels = append(s.profileLabels, key, labels[key])
+	}
+	return s
+}
+
+// profiled calls f with the goroutine and req labelled by the profiling
+// labels of the Sling, if any.
+func (s *Sling) profiled(req *http.Request, f func(req *http.Request)) {
+	if s.profileLabels == nil {
+		f(req)
+		return
+	}
+	pprof.Do(req.Context(), pprof.Labels(s.profileLabels...), func(ctx context.Context) {
+		f(req.WithContext(ctx))
+	})
+}
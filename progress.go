@@ -0,0 +1,54 @@
+package sling
+
+import (
+	"context"
+	"io"
+)
+
+// ProgressFunc is called as a response body is downloaded with the number
+// of bytes received so far and the Content-Length of the body, -1 if
+// unknown.
+type ProgressFunc func(received, total int64)
+
+type progressKey struct{}
+
+// DownloadProgress calls progress as response bodies are read, before they
+// are decoded, so CLIs can show the progress of large buffered downloads
+// such as JSON exports:
+//
+//	s.DownloadProgress(func(received, total int64) {
+//		fmt.Fprintf(os.Stderr, "\r%d/%d bytes", received, total)
+//	})
+//
+// progress is called from the goroutine sending the request after each
+// read of the body. Bodies are read by the default
+// Doer; custom Doers don't report progress unless they wrap it. A nil
+// progress disables reporting.
+func (s *Sling) DownloadProgress(progress ProgressFunc) *Sling {
+	s.checkMutable()
+	s.progress = progress
+	return s
+}
+
+// progressFromContext returns the ProgressFunc of the request sent with ctx.
+func progressFromContext(ctx context.Context) ProgressFunc {
+	progress, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return progress
+}
+
+// progressBody reports the progress of reading a response body.
+type progressBody struct {
+	io.ReadCloser
+	progress ProgressFunc
+	received int64
+	total    int64
+}
+
+func (b *progressBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.received += int64(n)
+	if n > 0 {
+		b.progress(b.received, b.total)
+	}
+	return n, err
+}
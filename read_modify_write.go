@@ -0,0 +1,70 @@
+package sling
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrConcurrentModification is returned by ReadModifyWrite when the resource
+// kept being modified by others (412 Precondition Failed) for all attempts.
+var ErrConcurrentModification = errors.New("sling: resource modified concurrently")
+
+// ReadModifyWrite updates the resource at the URL of s with optimistic
+// concurrency control: it GETs the resource into a T, captures its ETag,
+// applies mutate, then PUTs the JSON encoded result with If-Match. When the
+// PUT fails with 412 Precondition Failed, the resource is read again and
+// mutate reapplied, up to attempts times (3 if attempts <= 0), after which
+// ErrConcurrentModification is returned.
+//
+//	user, _, err := sling.ReadModifyWrite(api.New().Path("users/1"), 5, func(u *User) error {
+//		u.Roles = append(u.Roles, "admin")
+//		return nil
+//	})
+//
+// It returns the written resource, decoded from the PUT response when it has
+// a body, with the last response. Errors of mutate abort the update and are
+// returned as is; other failure responses are returned as *StatusError.
+func ReadModifyWrite[T any](s *Sling, attempts int, mutate func(resource *T) error) (*T, *Response, error) {
+	if attempts <= 0 {
+		attempts = 3
+	}
+	var resp *Response
+	for i := 0; i < attempts; i++ {
+		resource := new(T)
+		get := s.New()
+		get.method = http.MethodGet
+		var err error
+		resp, err = get.ReceiveSuccess(resource)
+		if err != nil {
+			return nil, resp, err
+		}
+		if !get.isSuccess(resp.Response) {
+			return nil, resp, newStatusError(resp)
+		}
+		etag := resp.Header.Get("ETag")
+		if etag == "" {
+			return nil, resp, errors.New("sling: resource has no ETag")
+		}
+		if err := mutate(resource); err != nil {
+			return nil, resp, err
+		}
+
+		put := s.New().BodyJSON(resource).SetHeader("If-Match", etag)
+		put.method = http.MethodPut
+		written := new(T)
+		resp, err = put.ReceiveSuccess(written)
+		if err != nil {
+			return nil, resp, err
+		}
+		switch {
+		case resp.StatusCode == http.StatusPreconditionFailed:
+			continue
+		case !put.isSuccess(resp.Response):
+			return nil, resp, newStatusError(resp)
+		case len(resp.RawData) == 0 && !resp.Spooled():
+			return resource, resp, nil
+		}
+		return written, resp, nil
+	}
+	return nil, resp, ErrConcurrentModification
+}
@@ -0,0 +1,135 @@
+package sling
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// redactedValue replaces redacted JSON values.
+const redactedValue = "[REDACTED]"
+
+// Redactor masks the values at JSON paths of payloads, see Redact.
+type Redactor struct {
+	paths [][]string
+}
+
+// jsonPathIndexRe matches array indexes and wildcards of JSON paths, e.g.
+// "[0]" or "[*]".
+var jsonPathIndexRe = regexp.MustCompile(`\[(\d+|\*)\]`)
+
+// NewRedactor returns a Redactor masking the values at paths. Paths are
+// dot separated like for Response.GetPath, with an optional "$." prefix,
+// numeric segments indexing arrays and "*" matching all keys or elements,
+// e.g. "$.card.number", "$.users[*].ssn" or "users.*.ssn".
+func NewRedactor(paths ...string) *Redactor {
+	r := &Redactor{}
+	r.add(paths)
+	return r
+}
+
+func (r *Redactor) add(paths []string) {
+	for _, path := range paths {
+		path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+		path = jsonPathIndexRe.ReplaceAllString(path, ".$1")
+		if path != "" {
+			r.paths = append(r.paths, splitJSONPath(path))
+		}
+	}
+}
+
+// Redact returns a copy of the JSON payload with the values at the paths of
+// the Redactor replaced by "[REDACTED]". Payloads which aren't JSON, or
+// have none of the paths, are returned as is. A nil Redactor returns the
+// payload as is.
+func (r *Redactor) Redact(payload []byte) []byte {
+	if r == nil || len(r.paths) == 0 {
+		return payload
+	}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return payload
+	}
+	redacted := false
+	for _, path := range r.paths {
+		if redactPath(v, path) {
+			redacted = true
+		}
+	}
+	if !redacted {
+		return payload
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return payload
+	}
+	return b
+}
+
+// redactPath replaces the values at path within v, reporting whether any
+// was found.
+func redactPath(v interface{}, path []string) bool {
+	segment, last := path[0], len(path) == 1
+	found := false
+	switch node := v.(type) {
+	case map[string]interface{}:
+		for key, child := range node {
+			if segment != "*" && segment != key {
+				continue
+			}
+			if last {
+				node[key] = redactedValue
+				found = true
+			} else if redactPath(child, path[1:]) {
+				found = true
+			}
+		}
+	case []interface{}:
+		for i, child := range node {
+			if segment != "*" && segment != strconv.Itoa(i) {
+				continue
+			}
+			if last {
+				node[i] = redactedValue
+				found = true
+			} else if redactPath(child, path[1:]) {
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+// Redact masks the values at the JSON paths (see NewRedactor) of response
+// payloads captured in errors (see StatusError), and makes the Redactor
+// available to logging Middlewares and Doers through RedactorFromContext,
+// so that redaction is declared once per route rather than per logging
+// integration:
+//
+//	payments := api.New().Path("payments/").Redact("$.card.number", "$.card.cvc")
+//
+// Paths add to those inherited from the parent Sling.
+func (s *Sling) Redact(paths ...string) *Sling {
+	s.checkMutable()
+	r := &Redactor{}
+	if s.redactor != nil {
+		r.paths = append(r.paths, s.redactor.paths...)
+	}
+	r.add(paths)
+	s.redactor = r
+	return s
+}
+
+type redactorKey struct{}
+
+// RedactorFromContext returns the Redactor of the Sling sending the request
+// with ctx (see Redact), nil if none.
+func RedactorFromContext(ctx context.Context) *Redactor {
+	r, _ := ctx.Value(redactorKey{}).(*Redactor)
+	return r
+}
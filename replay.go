@@ -0,0 +1,131 @@
+package sling
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrReplayedRequest is returned (wrapped) by ReplayVerifier.Verify for
+// requests with a missing, stale or reused timestamp or nonce.
+var ErrReplayedRequest = errors.New("sling: replayed request")
+
+// ReplayProtection describes the timestamp and nonce headers protecting
+// signed requests against replays, see ProtectReplay.
+type ReplayProtection struct {
+	// TimestampHeader carries the unix time of the request in seconds,
+	// "X-Timestamp" if empty.
+	TimestampHeader string
+	// NonceHeader carries a random nonce unique to the request, "X-Nonce" if
+	// empty.
+	NonceHeader string
+	// MaxSkew is the maximum difference between the timestamp of a request
+	// and the clock of its verifier, 5 minutes if 0.
+	MaxSkew time.Duration
+}
+
+func (p ReplayProtection) timestampHeader() string {
+	if p.TimestampHeader == "" {
+		return "X-Timestamp"
+	}
+	return p.TimestampHeader
+}
+
+func (p ReplayProtection) nonceHeader() string {
+	if p.NonceHeader == "" {
+		return "X-Nonce"
+	}
+	return p.NonceHeader
+}
+
+func (p ReplayProtection) maxSkew() time.Duration {
+	if p.MaxSkew <= 0 {
+		return 5 * time.Minute
+	}
+	return p.MaxSkew
+}
+
+// ProtectReplay sets fresh timestamp and nonce headers on each attempt at
+// sending a request, retries included, as required by most partner API
+// signature schemes. The headers are set above the middlewares of
+// UseAttempt, so signers must be added with UseAttempt to sign them:
+//
+//	api := sling.New().AutoRetry().ProtectReplay(sling.ReplayProtection{}).UseAttempt(signer.Middleware())
+//
+// Pass a zero ReplayProtection for the default headers.
+func (s *Sling) ProtectReplay(p ReplayProtection) *Sling {
+	s.checkMutable()
+	s.replay = &p
+	return s
+}
+
+// middleware returns a Middleware sending requests with fresh timestamp and
+// nonce headers.
+func (p ReplayProtection) middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, []byte, error) {
+			nonce := make([]byte, 16)
+			if _, err := rand.Read(nonce); err != nil {
+				return nil, nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Header.Set(p.timestampHeader(), strconv.FormatInt(time.Now().Unix(), 10))
+			req.Header.Set(p.nonceHeader(), hex.EncodeToString(nonce))
+			return next.Do(req)
+		})
+	}
+}
+
+// ReplayVerifier verifies the timestamp and nonce headers of requests, e.g.
+// in tests of signed clients or fake servers. It remembers the nonces of
+// verified requests as long as their timestamp is within MaxSkew.
+type ReplayVerifier struct {
+	protection ReplayProtection
+	// now returns the current time, overridden in tests
+	now func() time.Time
+
+	mu     sync.Mutex
+	nonces map[string]time.Time
+}
+
+// NewReplayVerifier returns a ReplayVerifier of requests protected by p.
+func NewReplayVerifier(p ReplayProtection) *ReplayVerifier {
+	return &ReplayVerifier{protection: p, now: time.Now, nonces: make(map[string]time.Time)}
+}
+
+// Verify returns an error wrapping ErrReplayedRequest if the timestamp of
+// req is missing or more than MaxSkew away from now, or if its nonce is
+// missing or was already verified.
+func (v *ReplayVerifier) Verify(req *http.Request) error {
+	header := v.protection.timestampHeader()
+	seconds, err := strconv.ParseInt(req.Header.Get(header), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid %s header %q", ErrReplayedRequest, header, req.Header.Get(header))
+	}
+	now, timestamp, maxSkew := v.now(), time.Unix(seconds, 0), v.protection.maxSkew()
+	if skew := now.Sub(timestamp); skew > maxSkew || skew < -maxSkew {
+		return fmt.Errorf("%w: timestamp %s is %s away from now", ErrReplayedRequest, timestamp.UTC().Format(time.RFC3339), skew)
+	}
+	nonce := req.Header.Get(v.protection.nonceHeader())
+	if nonce == "" {
+		return fmt.Errorf("%w: missing %s header", ErrReplayedRequest, v.protection.nonceHeader())
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for seen, expires := range v.nonces {
+		if now.After(expires) {
+			delete(v.nonces, seen)
+		}
+	}
+	if _, ok := v.nonces[nonce]; ok {
+		return fmt.Errorf("%w: nonce %q already used", ErrReplayedRequest, nonce)
+	}
+	v.nonces[nonce] = timestamp.Add(maxSkew)
+	return nil
+}
@@ -1,29 +1,186 @@
 package sling
 
 import (
+	"bytes"
+	"context"
+	"io"
 	"net/http"
+	"time"
 )
 
 // Raw is response's raw data
 type Raw []byte
 
-// Response is a http response wrapper
+// Response is a http response wrapper. Its Body has already been read into
+// RawData by the Doer and is replaced by a reader over RawData, so code
+// expecting a standard http.Response body can still read it.
+//
+// Bodies spooled to disk (see Sling.SpillToDisk) have a nil RawData and a
+// file backed Body instead. Closing the Body removes the file.
 type Response struct {
 	*http.Response
 	RawData []byte
+
+	spool *spoolFile
+	// unread body of a streamed response, see NDJSON
+	stream *streamBody
+	// when the response was received, see ClockSkew
+	received time.Time
+	// masks body snippets of errors, see Redact
+	redactor *Redactor
+	// encoding and sizes of the body, see Decompress
+	compression *CompressionStats
 }
 
 func NewResponse(response *http.Response, rawData []byte) *Response {
-	return &Response{
+	r := &Response{
 		Response: response,
 		RawData:  rawData,
+		received: time.Now(),
+	}
+	if response != nil {
+		r.spool, _ = response.Body.(*spoolFile)
+		r.stream, _ = response.Body.(*streamBody)
+	}
+	r.ResetBody()
+	return r
+}
+
+// ResetBody rewinds the response Body to its start so it can be read again.
+// Streamed bodies (see NDJSON) are replayed once read to their end if they
+// are at most 1 MiB, and are otherwise left consumed.
+func (r *Response) ResetBody() {
+	if r.Response == nil {
+		return
+	}
+	if r.spool != nil {
+		r.spool.Seek(0, io.SeekStart)
+		return
+	}
+	if r.stream != nil {
+		if r.stream.replayable() {
+			r.Body = replayBody(r.stream.kept)
+		}
+		return
+	}
+	r.Body = replayBody(r.RawData)
+}
+
+// Spooled reports whether the body was spooled to a temporary file rather
+// than buffered into RawData.
+func (r *Response) Spooled() bool {
+	return r.spool != nil
+}
+
+// Reader returns the body as an io.ReadSeekCloser positioned at its start,
+// backed by either RawData or the spooled file. Closing a spooled body's
+// Reader removes the file.
+func (r *Response) Reader() io.ReadSeekCloser {
+	if r.spool != nil {
+		r.spool.Seek(0, io.SeekStart)
+		return r.spool
+	}
+	return nopSeekCloser{bytes.NewReader(r.RawData)}
+}
+
+// NextPage returns the URL of the next page of a paginated response, the
+// target of its Link header with rel="next" (RFC 8288) resolved against the
+// request URL, or "" on the last page.
+func (r *Response) NextPage() string {
+	if r.Response == nil {
+		return ""
+	}
+	next := parseLinks(r.Header.Values("Link"))["next"]
+	if next == "" || r.Request == nil || r.Request.URL == nil {
+		return next
+	}
+	target, err := r.Request.URL.Parse(next)
+	if err != nil {
+		return next
 	}
+	return target.String()
+}
+
+// bytes returns the body, reading it back from disk for spooled responses.
+func (r *Response) bytes() ([]byte, error) {
+	if r.spool == nil {
+		return r.RawData, nil
+	}
+	defer r.ResetBody()
+	return io.ReadAll(r.Reader())
+}
+
+// decode decodes the body into the value pointed to by v. Spooled bodies are
+// streamed into decoders implementing ReaderDecoder. When ctx is cancellable,
+// large buffered bodies are decoded so that decoding stops once ctx is done:
+// JSON arrays element by element (see jsonDecoder.decodeContext), and other
+// ReaderDecoders through a contextReader. Bodies are passed as is to
+// decoders which would buffer them whole anyway (see buffersWholeValue),
+// which are not interrupted.
+func (r *Response) decode(ctx context.Context, decoder ResponseDecoder, v interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	rd, isReaderDecoder := decoder.(ReaderDecoder)
+	switch {
+	case r.spool != nil && isReaderDecoder:
+		defer r.ResetBody()
+		return rd.DecodeReader(contextReader{ctx: ctx, r: r.Reader()}, v)
+	case r.spool != nil:
+		data, err := r.bytes()
+		if err != nil {
+			return err
+		}
+		return decoder.Decode(data, v)
+	case ctx.Done() != nil && len(r.RawData) > contextDecodeThreshold:
+		if cd, ok := decoder.(contextDecoder); ok {
+			return cd.decodeContext(ctx, r.RawData, v)
+		}
+		if isReaderDecoder && !buffersWholeValue(decoder) {
+			return rd.DecodeReader(contextReader{ctx: ctx, r: bytes.NewReader(r.RawData)}, v)
+		}
+	}
+	return decoder.Decode(r.RawData, v)
+}
+
+// replayBody returns a ReadCloser over rawData.
+func replayBody(rawData []byte) io.ReadCloser {
+	return io.NopCloser(bytes.NewReader(rawData))
 }
 
 // SuccessDecider decide should we decode the response or not
 type SuccessDecider func(*http.Response) bool
 
-// DecodeOnSuccess decide that we should decode on success response (http code 2xx)
+// DecodeOnSuccess decide that we should decode on success response (http code 2xx).
+// Redirection responses (3xx) are treated as failures. Note that http.Client
+// follows redirects, so a 301/302/303/307/308 is only seen when redirects are
+// not followed (see http.Client.CheckRedirect), and 304 Not Modified
+// responses are never decoded as they have no body.
 func DecodeOnSuccess(resp *http.Response) bool {
 	return 200 <= resp.StatusCode && resp.StatusCode <= 299
 }
+
+// Decode2xxAnd3xx decide that we should decode on success and redirection
+// responses (http code 2xx and 3xx), for APIs returning meaningful bodies
+// with 3xx codes.
+func Decode2xxAnd3xx(resp *http.Response) bool {
+	return 200 <= resp.StatusCode && resp.StatusCode <= 399
+}
+
+// DecodeAlways decide that every response is a success, so its body is always
+// decoded into the success value.
+func DecodeAlways(resp *http.Response) bool {
+	return true
+}
+
+// DecodeOnStatuses returns a SuccessDecider treating only the given status
+// codes as success.
+func DecodeOnStatuses(statuses ...int) SuccessDecider {
+	set := make(map[int]bool, len(statuses))
+	for _, status := range statuses {
+		set[status] = true
+	}
+	return func(resp *http.Response) bool {
+		return set[resp.StatusCode]
+	}
+}
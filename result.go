@@ -0,0 +1,83 @@
+package sling
+
+import "net/http"
+
+// Result holds the outcome of a request decoded into a value of type T. It
+// allows chaining API calls without juggling nil pointers, e.g.
+//
+//	user := sling.ReceiveResult[User](api.New().Get("users/1"))
+//	orders := sling.AndThen(user, func(u User) sling.Result[[]Order] {
+//		return sling.ReceiveResult[[]Order](api.New().Get(u.OrdersURL))
+//	})
+type Result[T any] struct {
+	// Value is the decoded success response.
+	Value T
+	// Response is the received response, it may be nil if the request could
+	// not be sent.
+	Response *Response
+	// Err is any error creating the request, sending it or decoding the
+	// response, or a *StatusError for responses which aren't a success
+	// (see WithSuccessDecider).
+	Err error
+}
+
+// ReceiveResult creates a new HTTP request from the Sling and returns a Result
+// with success responses decoded into its Value.
+func ReceiveResult[T any](s *Sling, opts ...PerRequest) Result[T] {
+	req, err := s.Request()
+	if err != nil {
+		return Result[T]{Err: err}
+	}
+	return DoResult[T](s, req, opts...)
+}
+
+// DoResult sends the given HTTP request with the Sling and returns a Result
+// with success responses decoded into its Value. Other responses fail with a
+// *StatusError.
+func DoResult[T any](s *Sling, req *http.Request, opts ...PerRequest) Result[T] {
+	var r Result[T]
+	r.Response, r.Err = s.Do(req, &r.Value, nil, opts...)
+	if r.Err == nil && r.Response != nil && !resultSuccess(s, opts)(r.Response.Response) {
+		r.Err = newStatusError(r.Response)
+	}
+	return r
+}
+
+// resultSuccess returns the success decider of the Sling, or of opts if
+// they override it.
+func resultSuccess(s *Sling, opts []PerRequest) SuccessDecider {
+	if override := mergePerRequest(opts); override.SuccessDecider != nil {
+		return override.SuccessDecider
+	}
+	return s.isSuccess
+}
+
+// Ok reports whether the Result carries no error.
+func (r Result[T]) Ok() bool {
+	return r.Err == nil
+}
+
+// Unwrap returns the Value and Err of the Result.
+func (r Result[T]) Unwrap() (T, error) {
+	return r.Value, r.Err
+}
+
+// Map applies f to the Value of a successful Result. Failed Results are
+// propagated without calling f.
+func Map[T, U any](r Result[T], f func(T) (U, error)) Result[U] {
+	res := Result[U]{Response: r.Response, Err: r.Err}
+	if r.Err != nil {
+		return res
+	}
+	res.Value, res.Err = f(r.Value)
+	return res
+}
+
+// AndThen chains a further call on the Value of a successful Result. Failed
+// Results are propagated without calling f.
+func AndThen[T, U any](r Result[T], f func(T) Result[U]) Result[U] {
+	if r.Err != nil {
+		return Result[U]{Response: r.Response, Err: r.Err}
+	}
+	return f(r.Value)
+}
@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"context"
 	crand "crypto/rand"
-	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -14,6 +14,8 @@ import (
 	"regexp"
 	"strconv"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
@@ -149,7 +151,8 @@ func DefaultRetryPolicy(ctx context.Context, resp *http.Response, err error) (bo
 	}
 
 	if err != nil {
-		if v, ok := err.(*url.Error); ok {
+		var v *url.Error
+		if errors.As(err, &v) {
 			// Don't retry if the error was due to too many redirects.
 			if redirectsErrorRe.MatchString(v.Error()) {
 				return false, nil
@@ -161,7 +164,7 @@ func DefaultRetryPolicy(ctx context.Context, resp *http.Response, err error) (bo
 			}
 
 			// Don't retry if the error was due to TLS cert verification failure.
-			if _, ok := v.Err.(x509.UnknownAuthorityError); ok {
+			if ClassifyNetError(v) == NetErrorTLSVerification {
 				return false, nil
 			}
 		}
@@ -198,7 +201,8 @@ func ErrorPropagatedRetryPolicy(ctx context.Context, resp *http.Response, err er
 	}
 
 	if err != nil {
-		if v, ok := err.(*url.Error); ok {
+		var v *url.Error
+		if errors.As(err, &v) {
 			// Don't retry if the error was due to too many redirects.
 			if redirectsErrorRe.MatchString(v.Error()) {
 				return false, v
@@ -210,7 +214,7 @@ func ErrorPropagatedRetryPolicy(ctx context.Context, resp *http.Response, err er
 			}
 
 			// Don't retry if the error was due to TLS cert verification failure.
-			if _, ok := v.Err.(x509.UnknownAuthorityError); ok {
+			if ClassifyNetError(v) == NetErrorTLSVerification {
 				return false, v
 			}
 		}
@@ -367,6 +371,13 @@ func getBodyReaderAndContentLength(rawBody interface{}) (ReaderFunc, int64, erro
 
 // FromRequest wraps an http.Request in a retryablehttp.Request
 func FromRequest(r *http.Request) (*Request, error) {
+	// Bodies which can be recreated, such as files (see Sling.BodyFile), are
+	// streamed again on each attempt instead of being buffered.
+	if r.GetBody != nil && r.Body != nil && r.Body != http.NoBody {
+		r.Body.Close()
+		getBody := r.GetBody
+		return &Request{func() (io.Reader, error) { return getBody() }, r}, nil
+	}
 	bodyReader, _, err := getBodyReaderAndContentLength(r.Body)
 	if err != nil {
 		return nil, err
@@ -385,6 +396,12 @@ func (c *RetryDoer) Do(req *http.Request) (*http.Response, []byte, error) {
 
 // Try to read the response body so we can reuse this connection.
 func (c *RetryDoer) drainBody(body io.ReadCloser) error {
+	return drainBody(body)
+}
+
+// drainBody reads up to respReadLimit bytes of body, so that its keep-alive
+// connection can be reused, and closes it.
+func drainBody(body io.ReadCloser) error {
 	defer body.Close()
 	_, err := io.Copy(io.Discard, io.LimitReader(body, respReadLimit))
 	return err
@@ -403,6 +420,11 @@ func (c *RetryDoer) DoCustom(req *Request) (*http.Response, []byte, error) {
 
 	logger.WithFields(Fields{"method": req.Method, "url": req.URL}).Info("performing request")
 
+	checkRetry := c.CheckRetry
+	if policy, ok := retryPolicyFromContext(req.Context()); ok {
+		checkRetry = policy
+	}
+
 	var resp *http.Response
 	var attempt int
 	var shouldRetry bool
@@ -426,7 +448,7 @@ func (c *RetryDoer) DoCustom(req *Request) (*http.Response, []byte, error) {
 		}
 
 		// Check if we should continue with retries.
-		shouldRetry, checkErr = c.CheckRetry(req.Context(), resp, doErr)
+		shouldRetry, checkErr = checkRetry(req.Context(), resp, doErr)
 		if doErr != nil {
 			logger.WithFields(Fields{"method": req.Method, "url": req.URL}).Error("retry check failed")
 		}
@@ -456,6 +478,10 @@ func (c *RetryDoer) DoCustom(req *Request) (*http.Response, []byte, error) {
 			desc = fmt.Sprintf("%s (status: %d)", desc, code)
 		}
 		logger.WithFields(Fields{"request": desc, "timeout": wait, "remaining": remain}).Info("retrying request")
+		AddSpanEvent(req.Context(), SpanEventRetry,
+			attribute.Int("sling.retry.attempt", attempt),
+			attribute.Int("http.response.status_code", code),
+			attribute.String("sling.retry.wait", wait.String()))
 		select {
 		case <-req.Context().Done():
 			return nil, nil, req.Context().Err()
@@ -0,0 +1,118 @@
+package sling
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidSignature is returned (wrapped) for responses whose signature is
+// missing or does not verify, see VerifySignatures.
+var ErrInvalidSignature = errors.New("sling: invalid response signature")
+
+// SignatureScheme reports whether signature is a valid signature of message
+// with key.
+type SignatureScheme func(key, message, signature []byte) bool
+
+// HMACSHA256 verifies HMAC-SHA256 signatures, the key being the shared
+// secret.
+func HMACSHA256(key, message, signature []byte) bool {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(message)
+	return hmac.Equal(mac.Sum(nil), signature)
+}
+
+// Ed25519 verifies Ed25519 signatures, the key being the ed25519 public key.
+func Ed25519(key, message, signature []byte) bool {
+	return len(key) == ed25519.PublicKeySize && ed25519.Verify(ed25519.PublicKey(key), message, signature)
+}
+
+// ResponseVerifier describes how responses are signed, see VerifySignatures.
+type ResponseVerifier struct {
+	// Header carries the signature of the response body, hex or base64
+	// encoded, e.g. "X-Signature".
+	Header string
+	// Prefix is stripped from the signature header value, e.g. "sha256=".
+	Prefix string
+	// Scheme verifies signatures, e.g. HMACSHA256 or Ed25519.
+	Scheme SignatureScheme
+	// KeyIDHeader optionally names the header carrying the id of the
+	// signing key, passed to Key.
+	KeyIDHeader string
+	// Key returns the key identified by keyID (empty without KeyIDHeader).
+	Key func(keyID string) ([]byte, error)
+}
+
+// VerifySignatures verifies the signature of every response body before it
+// is decoded, for APIs signing their responses. Responses with a missing or
+// invalid signature return an error wrapping ErrInvalidSignature and are not
+// decoded:
+//
+//	s.VerifySignatures(sling.ResponseVerifier{
+//		Header: "X-Signature",
+//		Prefix: "sha256=",
+//		Scheme: sling.HMACSHA256,
+//		Key:    func(string) ([]byte, error) { return secret, nil },
+//	})
+//
+// Verified bodies are buffered, including NDJSON streams. A verifier
+// without Header, Scheme or Key disables verification.
+func (s *Sling) VerifySignatures(verifier ResponseVerifier) *Sling {
+	s.checkMutable()
+	if verifier.Header == "" || verifier.Scheme == nil || verifier.Key == nil {
+		s.verifier = nil
+		return s
+	}
+	s.verifier = &verifier
+	return s
+}
+
+// verify checks the signature of resp.
+func (v *ResponseVerifier) verify(resp *Response) error {
+	value := strings.TrimSpace(resp.Header.Get(v.Header))
+	if value == "" {
+		return fmt.Errorf("%w: missing %s header", ErrInvalidSignature, v.Header)
+	}
+	signature, err := decodeSignature(strings.TrimPrefix(value, v.Prefix))
+	if err != nil {
+		return fmt.Errorf("%w: malformed %s header", ErrInvalidSignature, v.Header)
+	}
+	var keyID string
+	if v.KeyIDHeader != "" {
+		keyID = resp.Header.Get(v.KeyIDHeader)
+	}
+	key, err := v.Key(keyID)
+	if err != nil {
+		return err
+	}
+	if err := resp.buffer(); err != nil {
+		return err
+	}
+	body, err := resp.bytes()
+	if err != nil {
+		return err
+	}
+	if !v.Scheme(key, body, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// decodeSignature decodes a hex or base64 (standard or URL, padded or not)
+// encoded signature.
+func decodeSignature(value string) ([]byte, error) {
+	if b, err := hex.DecodeString(value); err == nil {
+		return b, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(value); err == nil {
+			return b, nil
+		}
+	}
+	return nil, errors.New("sling: signature is neither hex nor base64")
+}
@@ -0,0 +1,92 @@
+package sling
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// SignedHeaders declares which request headers a signature covers and how
+// they are canonicalized, since every partner API defines it slightly
+// differently. Request signing Middlewares sign the string returned by
+// Canonical and may emit the signed headers list with Emit.
+type SignedHeaders struct {
+	// Headers are the names of the signed headers, in signing order unless
+	// Sort is set. "Host" signs the request host. Headers missing from a
+	// request are skipped.
+	Headers []string
+	// Lowercase lowercases header names, e.g. "content-type".
+	Lowercase bool
+	// Trim trims header values and collapses their inner whitespace runs to
+	// single spaces.
+	Trim bool
+	// Sort sorts the signed headers by canonical name.
+	Sort bool
+	// ListHeader, if not empty, is the request header Emit sets to the
+	// signed headers list, e.g. "X-Signed-Headers".
+	ListHeader string
+	// ListSeparator separates the names of the signed headers list, ";" if
+	// empty.
+	ListSeparator string
+}
+
+// Canonical returns the canonical form of the signed headers of req, one
+// "name:value" line per header, and the list of their names joined with
+// ListSeparator. Multiple values of a header are joined with commas.
+func (h SignedHeaders) Canonical(req *http.Request) (canonical, list string) {
+	type header struct{ name, value string }
+	headers := make([]header, 0, len(h.Headers))
+	for _, name := range h.Headers {
+		var values []string
+		if strings.EqualFold(name, "Host") {
+			host := req.Host
+			if host == "" && req.URL != nil {
+				host = req.URL.Host
+			}
+			if host != "" {
+				values = []string{host}
+			}
+		} else {
+			values = append([]string(nil), req.Header.Values(name)...)
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if h.Trim {
+			for i, v := range values {
+				values[i] = strings.Join(strings.Fields(v), " ")
+			}
+		}
+		if h.Lowercase {
+			name = strings.ToLower(name)
+		} else {
+			name = http.CanonicalHeaderKey(name)
+		}
+		headers = append(headers, header{name: name, value: strings.Join(values, ",")})
+	}
+	if h.Sort {
+		sort.SliceStable(headers, func(i, j int) bool { return headers[i].name < headers[j].name })
+	}
+	var b strings.Builder
+	names := make([]string, len(headers))
+	for i, header := range headers {
+		b.WriteString(header.name)
+		b.WriteByte(':')
+		b.WriteString(header.value)
+		b.WriteByte('\n')
+		names[i] = header.name
+	}
+	separator := h.ListSeparator
+	if separator == "" {
+		separator = ";"
+	}
+	return b.String(), strings.Join(names, separator)
+}
+
+// Emit sets the ListHeader header of req to list, if ListHeader is not
+// empty.
+func (h SignedHeaders) Emit(req *http.Request, list string) {
+	if h.ListHeader != "" {
+		req.Header.Set(h.ListHeader, list)
+	}
+}
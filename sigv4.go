@@ -0,0 +1,298 @@
+package sling
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AWSCredentials are the credentials of AWS requests, see SigV4Signer.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken of temporary credentials, sent as X-Amz-Security-Token.
+	SessionToken string
+}
+
+// SigV4Signer signs requests with AWS Signature Version 4, so that Sling
+// can call AWS and S3 compatible APIs directly:
+//
+//	signer := &sling.SigV4Signer{Region: "eu-west-1", Service: "s3", Credentials: sling.StaticAWSCredentials(creds)}
+//	s3 := sling.New().Base("https://bucket.s3.eu-west-1.amazonaws.com/").Use(signer.Middleware())
+//
+// Signatures cover the host, X-Amz-* and Content-Type headers and the hash
+// of the final body, which is buffered.
+type SigV4Signer struct {
+	Region  string
+	Service string
+	// Credentials returns the credentials signing a request, e.g. refreshed
+	// temporary credentials.
+	Credentials func(ctx context.Context) (AWSCredentials, error)
+	// now returns the signing time, overridden in tests
+	now func() time.Time
+}
+
+// StaticAWSCredentials returns a SigV4Signer Credentials function always
+// returning credentials.
+func StaticAWSCredentials(credentials AWSCredentials) func(ctx context.Context) (AWSCredentials, error) {
+	return func(context.Context) (AWSCredentials, error) {
+		return credentials, nil
+	}
+}
+
+// Middleware returns a Middleware signing requests with s before they are
+// sent (see Use).
+func (s *SigV4Signer) Middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, []byte, error) {
+			req = req.Clone(req.Context())
+			if err := s.Sign(req); err != nil {
+				return nil, nil, err
+			}
+			return next.Do(req)
+		})
+	}
+}
+
+// Sign sets the X-Amz-Date, X-Amz-Security-Token (for temporary
+// credentials), X-Amz-Content-Sha256 (for S3) and Authorization headers of
+// req. The body of req is buffered to be hashed.
+func (s *SigV4Signer) Sign(req *http.Request) error {
+	if s.Credentials == nil {
+		return errors.New("sling: SigV4Signer without Credentials")
+	}
+	credentials, err := s.Credentials(req.Context())
+	if err != nil {
+		return err
+	}
+	payload, err := bufferBody(req)
+	if err != nil {
+		return err
+	}
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	t := now().UTC()
+	amzDate, date := t.Format("20060102T150405Z"), t.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	headers := SignedHeaders{Headers: []string{"Host", "X-Amz-Date"}, Lowercase: true, Trim: true, Sort: true}
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+		headers.Headers = append(headers.Headers, "X-Amz-Security-Token")
+	}
+	if s.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+		headers.Headers = append(headers.Headers, "X-Amz-Content-Sha256")
+	}
+	if req.Header.Get(hdrContentTypeKey) != "" {
+		headers.Headers = append(headers.Headers, hdrContentTypeKey)
+	}
+	canonicalHeaders, signedHeaders := headers.Canonical(req)
+	canonicalRequest := s.canonicalRequest(req, req.URL.Query(), canonicalHeaders, signedHeaders, payloadHash)
+	scope := s.scope(date)
+	signature := s.signature(credentials, t, canonicalRequest)
+	req.Header.Set(hdrAuthorizationKey, "AWS4-HMAC-SHA256 Credential="+credentials.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+	return nil
+}
+
+// maxPresignExpiry is the longest validity of presigned requests accepted by
+// AWS.
+const maxPresignExpiry = 7 * 24 * time.Hour
+
+// PresignedRequest is a request signed by SigV4Signer.Presign, to be sent
+// later by anyone holding it, e.g. a browser downloading or uploading an S3
+// object.
+type PresignedRequest struct {
+	Method string
+	// URL carries the signature in its query.
+	URL string
+	// Header holds the signed headers, besides Host, which the request must
+	// be sent with.
+	Header http.Header
+	// Expires is when the signature expires.
+	Expires time.Time
+}
+
+// Presign returns req signed with query parameters valid for expiry (up to 7
+// days), as for S3 presigned URLs, without sending it. The signature covers
+// the host and Content-Type headers. S3 requests sign an unsigned payload,
+// so that uploads may send any body, while other services sign the hash of
+// the buffered body.
+func (s *SigV4Signer) Presign(req *http.Request, expiry time.Duration) (*PresignedRequest, error) {
+	if s.Credentials == nil {
+		return nil, errors.New("sling: SigV4Signer without Credentials")
+	}
+	if expiry < time.Second || expiry > maxPresignExpiry {
+		return nil, fmt.Errorf("sling: presign expiry %s not within 1s and 7 days", expiry)
+	}
+	credentials, err := s.Credentials(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	payloadHash := "UNSIGNED-PAYLOAD"
+	if s.Service != "s3" {
+		payload, err := bufferBody(req)
+		if err != nil {
+			return nil, err
+		}
+		payloadHash = sha256Hex(payload)
+	}
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	t := now().UTC()
+
+	headers := SignedHeaders{Headers: []string{"Host"}, Lowercase: true, Trim: true, Sort: true}
+	header := make(http.Header)
+	if contentType := req.Header.Get(hdrContentTypeKey); contentType != "" {
+		headers.Headers = append(headers.Headers, hdrContentTypeKey)
+		header.Set(hdrContentTypeKey, contentType)
+	}
+	canonicalHeaders, signedHeaders := headers.Canonical(req)
+	query := req.URL.Query()
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", credentials.AccessKeyID+"/"+s.scope(t.Format("20060102")))
+	query.Set("X-Amz-Date", t.Format("20060102T150405Z"))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry/time.Second)))
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+	if credentials.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+	canonicalRequest := s.canonicalRequest(req, query, canonicalHeaders, signedHeaders, payloadHash)
+	signature := s.signature(credentials, t, canonicalRequest)
+
+	presigned := *req.URL
+	presigned.RawQuery = canonicalQuery(query) + "&X-Amz-Signature=" + signature
+	return &PresignedRequest{Method: req.Method, URL: presigned.String(), Header: header, Expires: t.Add(expiry)}, nil
+}
+
+// canonicalRequest returns the SigV4 canonical request of req with query.
+func (s *SigV4Signer) canonicalRequest(req *http.Request, query url.Values, canonicalHeaders, signedHeaders, payloadHash string) string {
+	path := req.URL.Path
+	if path == "" {
+		path = "/"
+	}
+	canonicalURI := awsURIEncode(path, false)
+	if s.Service != "s3" {
+		// services other than S3 sign the double encoded path
+		canonicalURI = awsURIEncode(canonicalURI, false)
+	}
+	return strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		canonicalQuery(query),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+}
+
+// scope returns the credential scope of signatures made on date.
+func (s *SigV4Signer) scope(date string) string {
+	return date + "/" + s.Region + "/" + s.Service + "/aws4_request"
+}
+
+// signature returns the hex encoded signature of canonicalRequest made at t.
+func (s *SigV4Signer) signature(credentials AWSCredentials, t time.Time, canonicalRequest string) string {
+	date := t.Format("20060102")
+	stringToSign := "AWS4-HMAC-SHA256\n" + t.Format("20060102T150405Z") + "\n" + s.scope(date) + "\n" + sha256Hex([]byte(canonicalRequest))
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	for _, part := range []string{s.Region, s.Service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// bufferBody reads the body of req, replacing it by a replayable copy.
+func bufferBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	payload, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(payload))
+	req.Body = io.NopCloser(bytes.NewReader(payload))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(payload)), nil
+	}
+	return payload, nil
+}
+
+// canonicalQuery returns the query sorted by key and value, encoded as
+// required by SigV4.
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, awsURIEncode(key, true)+"="+awsURIEncode(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// awsURIEncode percent encodes all bytes of s but unreserved characters
+// (and slashes unless encodeSlash is set), as required by SigV4.
+func awsURIEncode(s string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&15])
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// Presign returns the request of the Sling (see Request) presigned by signer
+// for expiry, without sending it, e.g. to hand out a time-limited S3
+// download link:
+//
+//	link, err := s3.New().Get("reports/2024.pdf").Presign(signer, 15*time.Minute)
+//
+// See SigV4Signer.Presign.
+func (s *Sling) Presign(signer *SigV4Signer, expiry time.Duration) (*PresignedRequest, error) {
+	req, err := s.Request()
+	if err != nil {
+		return nil, err
+	}
+	return signer.Presign(req, expiry)
+}
@@ -3,18 +3,26 @@ package sling
 import (
 	"context"
 	"encoding/base64"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
-	goquery "github.com/google/go-querystring/query"
-	otelhttp "go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
-	jsonContentType = "application/json"
-	formContentType = "application/x-www-form-urlencoded"
+	jsonContentType  = "application/json"
+	formContentType  = "application/x-www-form-urlencoded"
+	protoContentType = "application/x-protobuf"
+	cborContentType  = "application/cbor"
+	yamlContentType  = "application/yaml"
 )
 
 const (
@@ -52,18 +60,88 @@ type Sling struct {
 	// url tagged query structs
 	queryStructs []interface{}
 	queryParams  map[string]string
+	// query parameters set, added or removed in order, see QueryParam
+	queryEdits []queryEdit
+	// header tagged structs, see HeaderStruct
+	headerStructs []interface{}
+	// query parameter set to a unique value on each request
+	cacheBustParam string
 	// body provider
 	bodyProvider BodyProvider
 	// response decoder
 	responseDecoder ResponseDecoder
+	// transformers applied to successfully decoded responses
+	transformers []ResponseTransformer
+	// middlewares wrapping httpClient, outermost first
+	middlewares []Middleware
+	// middlewares wrapping each attempt, see UseAttempt
+	attemptLayer []Middleware
+	// guarded Slings panic on builder calls once used, see GuardMutations
+	guarded bool
+	used    atomic.Bool
+	// memoized success values, see Memoize
+	memo *memoCache
+	// envelope field names, see Envelope
+	envelope *envelope
+	// attributes set on the active span, see SpanAttributes
+	spanAttributes []attribute.KeyValue
+	// header carrying the remaining deadline, see PropagateDeadline
+	deadlineHeader string
+	// clock skew logging, see CheckClockSkew
+	clockSkew *clockSkewCheck
+	// Content-Encoding applied to request bodies, see CompressBody
+	bodyEncoding ContentEncoding
+	// response handling of RPC style bodies, see BodyGraphQL and JSONRPC
+	protocol protocol
+	// response signature verification, see VerifySignatures
+	verifier *ResponseVerifier
+	// payload encryption, see Encrypt and Decrypt
+	encrypter BodyEncrypter
+	decrypter ResponseDecrypter
+	// pprof label key value pairs, see WithProfiling
+	profileLabels []string
+	// check grpc-status trailers, see CheckGrpcStatus
+	grpcStatus bool
+	// copy of response bodies, see TeeResponse
+	tee io.Writer
+	// timestamp and nonce headers, see ProtectReplay
+	replay *ReplayProtection
+	// rendering of time query and form values, see TimeFormat
+	timeFormat *timeFormat
+	// masking of captured payloads, see Redact
+	redactor *Redactor
+	// latency tracking, see AdaptiveTimeout
+	adaptiveTimeout *AdaptiveTimeout
+	// encoding of multi-valued query parameters, see ArrayEncoding
+	arrayEncoding ArrayEncoding
+	// misconfigurations of the builder, see Err
+	errs []error
+	// health of the endpoints, see TrackEndpointHealth
+	endpoints *endpointTracker
+	// partitioning by tenant, see TenantKey
+	tenantKey TenantKeyFunc
+	// response decompression, see Decompress
+	decompress *decompression
+	// download progress callback, see DownloadProgress
+	progress ProgressFunc
+	// bearer token resolved when requests are sent, see SetBearerAuthFunc
+	bearerToken TokenFunc
+	// headers computed when requests are built, see SetHeaderFunc
+	headerFuncs []headerFunc
+	// cookies sent with new requests, see SetCookie
+	cookies []*http.Cookie
+	// middlewares of the decoding layer, see UseDecoding
+	decodingLayer []DecodingMiddleware
+	// deprecation warning hook, see OnDeprecation
+	onDeprecation func(req *http.Request, d Deprecation)
+	// names of the API keys, see SetAPIKey
+	apiKeys []apiKey
 
 	ctx       context.Context
 	isSuccess SuccessDecider
 }
 
-var defaultClient = NewHttpWrapper(&http.Client{
-	Transport: otelhttp.NewTransport(http.DefaultTransport),
-})
+var defaultClient = newTransportWrapper(http.Client{}, http.DefaultTransport.(*http.Transport))
 
 // New returns a new Sling with an http DefaultClient.
 func New() *Sling {
@@ -104,7 +182,43 @@ func (s *Sling) New() *Sling {
 		queryStructs:    append([]interface{}{}, s.queryStructs...),
 		bodyProvider:    s.bodyProvider,
 		queryParams:     s.queryParams,
+		queryEdits:      append([]queryEdit{}, s.queryEdits...),
+		headerStructs:   append([]interface{}(nil), s.headerStructs...),
+		cacheBustParam:  s.cacheBustParam,
 		responseDecoder: s.responseDecoder,
+		transformers:    append([]ResponseTransformer{}, s.transformers...),
+		middlewares:     append([]Middleware{}, s.middlewares...),
+		attemptLayer:    append([]Middleware{}, s.attemptLayer...),
+		guarded:         s.guarded,
+		memo:            s.memo,
+		envelope:        s.envelope,
+		spanAttributes:  append([]attribute.KeyValue{}, s.spanAttributes...),
+		deadlineHeader:  s.deadlineHeader,
+		clockSkew:       s.clockSkew,
+		bodyEncoding:    s.bodyEncoding,
+		protocol:        s.protocol,
+		verifier:        s.verifier,
+		encrypter:       s.encrypter,
+		decrypter:       s.decrypter,
+		profileLabels:   s.profileLabels,
+		grpcStatus:      s.grpcStatus,
+		tee:             s.tee,
+		replay:          s.replay,
+		timeFormat:      s.timeFormat,
+		redactor:        s.redactor,
+		adaptiveTimeout: s.adaptiveTimeout,
+		arrayEncoding:   s.arrayEncoding,
+		errs:            append([]error{}, s.errs...),
+		endpoints:       s.endpoints,
+		tenantKey:       s.tenantKey,
+		decompress:      s.decompress,
+		progress:        s.progress,
+		bearerToken:     s.bearerToken,
+		headerFuncs:     append([]headerFunc(nil), s.headerFuncs...),
+		cookies:         s.cookies,
+		onDeprecation:   s.onDeprecation,
+		apiKeys:         append([]apiKey(nil), s.apiKeys...),
+		decodingLayer:   append([]DecodingMiddleware(nil), s.decodingLayer...),
 		isSuccess:       s.isSuccess,
 	}
 }
@@ -122,7 +236,14 @@ func (s *Sling) Client(httpWrapper *HttpWrapper) *Sling {
 
 // Doer sets the custom Doer implementation used to do requests.
 // If a nil client is given, the http.DefaultClient will be used.
+//
+// Transport settings such as MaxResponseHeaderBytes, Proxy or TLSConfig
+// configure a copy of the transport of an HttpWrapper, below any RetryDoer
+// or ThrottleDoer wrapping it, so Slings sharing the Doer are unaffected.
+// Other Doers and RoundTrippers are left as is and the setting is recorded
+// as a builder error (see Err).
 func (s *Sling) Doer(doer Doer) *Sling {
+	s.checkMutable()
 	if doer == nil {
 		s.httpClient = defaultClient
 	} else {
@@ -131,6 +252,45 @@ func (s *Sling) Doer(doer Doer) *Sling {
 	return s
 }
 
+// MaxResponseHeaderBytes limits the size of the response headers the
+// transport reads (see Doer). Responses exceeding it fail with a
+// *HeaderLimitError.
+func (s *Sling) MaxResponseHeaderBytes(n int64) *Sling {
+	s.checkMutable()
+	s.configureTransport("MaxResponseHeaderBytes", strconv.FormatInt(n, 10), func(t *http.Transport) {
+		t.MaxResponseHeaderBytes = n
+	})
+	return s
+}
+
+// MaxResponseHeaderCount limits the number of response header values.
+// Responses exceeding it fail with a *HeaderLimitError before their body is
+// read. A zero n removes the limit.
+func (s *Sling) MaxResponseHeaderCount(n int) *Sling {
+	s.checkMutable()
+	s.configureWrapper("MaxResponseHeaderCount", strconv.Itoa(n), func(h *HttpWrapper) *HttpWrapper {
+		c := h.clone()
+		c.maxHeaderCount = n
+		return c
+	})
+	return s
+}
+
+// SpillToDisk spools response bodies larger than threshold bytes to a
+// temporary file in dir (os.TempDir() if empty) instead of buffering them in
+// memory. Spooled responses have a nil RawData and a file backed Body which
+// removes the file when closed (see Response.Spooled).
+func (s *Sling) SpillToDisk(threshold int64, dir string) *Sling {
+	s.checkMutable()
+	s.configureWrapper("SpillToDisk", dir, func(h *HttpWrapper) *HttpWrapper {
+		c := h.clone()
+		c.spillThreshold = threshold
+		c.spillDir = dir
+		return c
+	})
+	return s
+}
+
 // Context method returns the Context if its already set in request
 // otherwise it creates new one using `context.Background()`.
 func (s *Sling) Context() context.Context {
@@ -141,6 +301,7 @@ func (s *Sling) Context() context.Context {
 }
 
 func (s *Sling) AutoRetry(opts ...RetryOption) *Sling {
+	s.checkMutable()
 	s.httpClient = NewRetryDoer(s.httpClient, opts...)
 	return s
 }
@@ -150,6 +311,7 @@ func (s *Sling) AutoRetry(opts ...RetryOption) *Sling {
 // See https://blog.golang.org/context article and the "context" package
 // documentation.
 func (s *Sling) SetContext(ctx context.Context) *Sling {
+	s.checkMutable()
 	s.ctx = ctx
 	return s
 }
@@ -158,63 +320,87 @@ func (s *Sling) SetContext(ctx context.Context) *Sling {
 
 // Head sets the Sling method to HEAD and sets the given pathURL.
 func (s *Sling) Head(pathURL string) *Sling {
+	s.checkMutable()
 	s.method = MethodHead
 	return s.Path(pathURL)
 }
 
 // Get sets the Sling method to GET and sets the given pathURL.
 func (s *Sling) Get(pathURL string) *Sling {
+	s.checkMutable()
 	s.method = MethodGet
 	return s.Path(pathURL)
 }
 
 // Post sets the Sling method to POST and sets the given pathURL.
 func (s *Sling) Post(pathURL string) *Sling {
+	s.checkMutable()
 	s.method = MethodPost
 	return s.Path(pathURL)
 }
 
 // Put sets the Sling method to PUT and sets the given pathURL.
 func (s *Sling) Put(pathURL string) *Sling {
+	s.checkMutable()
 	s.method = MethodPut
 	return s.Path(pathURL)
 }
 
 // Patch sets the Sling method to PATCH and sets the given pathURL.
 func (s *Sling) Patch(pathURL string) *Sling {
+	s.checkMutable()
 	s.method = MethodPatch
 	return s.Path(pathURL)
 }
 
 // Delete sets the Sling method to DELETE and sets the given pathURL.
 func (s *Sling) Delete(pathURL string) *Sling {
+	s.checkMutable()
 	s.method = MethodDelete
 	return s.Path(pathURL)
 }
 
 // Options sets the Sling method to OPTIONS and sets the given pathURL.
 func (s *Sling) Options(pathURL string) *Sling {
+	s.checkMutable()
 	s.method = MethodOptions
 	return s.Path(pathURL)
 }
 
 // Trace sets the Sling method to TRACE and sets the given pathURL.
 func (s *Sling) Trace(pathURL string) *Sling {
+	s.checkMutable()
 	s.method = MethodTrace
 	return s.Path(pathURL)
 }
 
 // Connect sets the Sling method to CONNECT and sets the given pathURL.
 func (s *Sling) Connect(pathURL string) *Sling {
+	s.checkMutable()
 	s.method = MethodConnect
 	return s.Path(pathURL)
 }
 
+// Method sets the Sling method to the given, possibly custom, HTTP method
+// (e.g. WebDAV PROPFIND or REPORT) and sets the given pathURL. Methods which
+// aren't valid HTTP tokens are ignored and an error is returned by Request
+// (see Err).
+func (s *Sling) Method(method, pathURL string) *Sling {
+	s.checkMutable()
+	if !isToken(method) {
+		s.addErr("Method", method, errors.New("invalid HTTP method"))
+		return s.Path(pathURL)
+	}
+	s.method = method
+	return s.Path(pathURL)
+}
+
 // Header
 
 // Add adds the key, value pair in Headers, appending values for existing keys
 // to the key's values. Header keys are canonicalized.
 func (s *Sling) AddHeader(key, value string) *Sling {
+	s.checkMutable()
 	s.header.Add(key, value)
 	return s
 }
@@ -222,10 +408,80 @@ func (s *Sling) AddHeader(key, value string) *Sling {
 // Set sets the key, value pair in Headers, replacing existing values
 // associated with key. Header keys are canonicalized.
 func (s *Sling) SetHeader(key, value string) *Sling {
+	s.checkMutable()
+	s.removeHeaderFuncs(key)
 	s.header.Set(key, value)
 	return s
 }
 
+// SetHeaderFunc sets the key header of each new request (see Request()) to
+// the value fn returns for the context of the Sling when the request is
+// built, e.g. for request IDs or timestamps:
+//
+//	s.SetHeaderFunc("X-Request-Id", func(ctx context.Context) string { return uuid.NewString() })
+//
+// The value replaces any value of key from Headers; an empty value leaves
+// them as is. Header keys are canonicalized.
+func (s *Sling) SetHeaderFunc(key string, fn func(ctx context.Context) string) *Sling {
+	s.checkMutable()
+	if fn == nil {
+		return s
+	}
+	s.removeHeaderFuncs(key)
+	s.headerFuncs = append(s.headerFuncs, headerFunc{key: http.CanonicalHeaderKey(key), fn: fn})
+	return s
+}
+
+// headerFunc computes the value of a header, see SetHeaderFunc.
+type headerFunc struct {
+	key string
+	fn  func(ctx context.Context) string
+}
+
+// removeHeaderFuncs removes the SetHeaderFunc functions of key.
+func (s *Sling) removeHeaderFuncs(key string) {
+	if len(s.headerFuncs) == 0 {
+		return
+	}
+	key = http.CanonicalHeaderKey(key)
+	funcs := s.headerFuncs[:0:0]
+	for _, f := range s.headerFuncs {
+		if f.key != key {
+			funcs = append(funcs, f)
+		}
+	}
+	s.headerFuncs = funcs
+}
+
+// setHeaderFuncs sets the headers of req computed by the SetHeaderFunc
+// functions.
+func (s *Sling) setHeaderFuncs(req *http.Request) {
+	for _, f := range s.headerFuncs {
+		if value := f.fn(req.Context()); value != "" {
+			req.Header.Set(f.key, value)
+		}
+	}
+}
+
+// RemoveHeader removes the values associated with key from Headers, and its
+// SetHeaderFunc, e.g. to drop the Authorization header inherited from a
+// parent Sling for a public endpoint. Header keys are canonicalized.
+func (s *Sling) RemoveHeader(key string) *Sling {
+	s.checkMutable()
+	s.removeHeaderFuncs(key)
+	s.header.Del(key)
+	return s
+}
+
+// ClearHeaders removes all Headers and SetHeaderFunc functions, including
+// those inherited from a parent Sling.
+func (s *Sling) ClearHeaders() *Sling {
+	s.checkMutable()
+	s.headerFuncs = nil
+	s.header = make(http.Header)
+	return s
+}
+
 // SetBasicAuth sets the Authorization header to use HTTP Basic Authentication
 // with the provided username and password. With HTTP Basic Authentication
 // the provided username and password are not encrypted.
@@ -241,28 +497,97 @@ func basicAuth(username, password string) string {
 }
 
 // SetBearerAuth sets the Authorization header to use HTTP Bearer Authentication
-// with the provided token.
+// with the provided token. Use SetBearerAuthFunc for tokens which expire.
 func (s *Sling) SetBearerAuth(token string) *Sling {
 	return s.SetHeader(hdrAuthorizationKey, "Bearer "+token)
 }
 
+// APIKeyLocation is where SetAPIKey sends an API key.
+type APIKeyLocation string
+
+const (
+	// APIKeyInHeader sends API keys in a header, e.g. X-API-Key.
+	APIKeyInHeader APIKeyLocation = "header"
+	// APIKeyInQuery sends API keys in a query parameter, e.g. api_key.
+	APIKeyInQuery APIKeyLocation = "query"
+)
+
+// SetAPIKey sends the API key value as the name header or query parameter
+// of new requests, depending on in:
+//
+//	s.SetAPIKey("X-API-Key", key, sling.APIKeyInHeader)
+//	s.SetAPIKey("api_key", key, sling.APIKeyInQuery)
+//
+// The key replaces any value of name inherited from a parent Sling. Other
+// locations are recorded as a builder error (see Err).
+func (s *Sling) SetAPIKey(name, value string, in APIKeyLocation) *Sling {
+	s.checkMutable()
+	if in != APIKeyInHeader && in != APIKeyInQuery {
+		s.addErr("SetAPIKey", string(in), errors.New("unknown API key location"))
+		return s
+	}
+	s.apiKeys = append(s.apiKeys, apiKey{name: name, in: in})
+	if in == APIKeyInHeader {
+		return s.SetHeader(name, value)
+	}
+	return s.QueryParam(name, value)
+}
+
+// apiKey is the name and location of an API key, masked when rendering the
+// Sling (see GoString).
+type apiKey struct {
+	name string
+	in   APIKeyLocation
+}
+
+// WithSuccessDecider sets the SuccessDecider choosing whether responses are
+// decoded into successV or failureV. It is inherited by child Slings (see
+// New) and can be overridden for a single call with
+// PerRequest{SuccessDecider: isSuccess}. A nil isSuccess is ignored.
 func (s *Sling) WithSuccessDecider(isSuccess SuccessDecider) *Sling {
-	s.isSuccess = isSuccess
+	s.checkMutable()
+	if isSuccess != nil {
+		s.isSuccess = isSuccess
+	}
 	return s
 }
 
+// WrapSuccessDecider replaces the SuccessDecider with the one returned by
+// wrap, which receives the current (usually inherited) decider. It lets a
+// child Sling extend its parent's decider instead of replacing it, e.g.
+//
+//	child := parent.New().WrapSuccessDecider(func(parent sling.SuccessDecider) sling.SuccessDecider {
+//		return func(resp *http.Response) bool {
+//			return resp.StatusCode == http.StatusConflict || parent(resp)
+//		}
+//	})
+//
+// If wrap is nil or returns nil, the decider is left unmodified.
+func (s *Sling) WrapSuccessDecider(wrap func(parent SuccessDecider) SuccessDecider) *Sling {
+	if wrap == nil {
+		return s
+	}
+	return s.WithSuccessDecider(wrap(s.isSuccess))
+}
+
 // Url
 
 // Base sets the rawURL. If you intend to extend the url with Path,
 // baseUrl should be specified with a trailing slash.
 func (s *Sling) Base(rawURL string) *Sling {
+	s.checkMutable()
+	if _, err := url.Parse(rawURL); err != nil {
+		s.addErr("Base", rawURL, err)
+	}
 	s.rawURL = rawURL
 	return s
 }
 
 // Path extends the rawURL with the given path by resolving the reference to
-// an absolute URL. If parsing errors occur, the rawURL is left unmodified.
+// an absolute URL. If parsing errors occur, the rawURL is left unmodified
+// and the error is returned by Request (see Err).
 func (s *Sling) Path(path string) *Sling {
+	s.checkMutable()
 	baseURL, baseErr := url.Parse(s.rawURL)
 	pathURL, pathErr := url.Parse(path)
 	if baseErr == nil && pathErr == nil {
@@ -272,28 +597,167 @@ func (s *Sling) Path(path string) *Sling {
 		}
 		return s
 	}
+	s.addErr("Path", path, errors.Join(baseErr, pathErr))
 	return s
 }
 
+// Fragment sets the fragment of the rawURL, replacing any previous one. An
+// empty fragment removes it. Fragments are not sent in requests, but are
+// kept in the URL (see URL()). If parsing errors occur, the rawURL is left
+// unmodified and the error is returned by Request (see Err).
+func (s *Sling) Fragment(fragment string) *Sling {
+	s.checkMutable()
+	u, err := url.Parse(s.rawURL)
+	if err != nil {
+		s.addErr("Fragment", fragment, err)
+		return s
+	}
+	u.Fragment, u.RawFragment = fragment, ""
+	s.rawURL = u.String()
+	return s
+}
+
+// UserInfo sets the username and password of the rawURL, sent with Basic
+// Authentication by http.Client unless an Authorization header is set. An
+// empty password sets the username only, and empty username and password
+// remove the user info. If parsing errors occur, the rawURL is left
+// unmodified and the error is returned by Request (see Err).
+func (s *Sling) UserInfo(username, password string) *Sling {
+	s.checkMutable()
+	u, err := url.Parse(s.rawURL)
+	if err != nil {
+		s.addErr("UserInfo", username, err)
+		return s
+	}
+	switch {
+	case username == "" && password == "":
+		u.User = nil
+	case password == "":
+		u.User = url.User(username)
+	default:
+		u.User = url.UserPassword(username, password)
+	}
+	s.rawURL = u.String()
+	return s
+}
+
+// PathSegments extends the rawURL with the path of the given segments, each
+// escaped so that user input can't alter the path (e.g. "../admin" or
+// "a/b" stay single segments), see Path:
+//
+//	s.Get("api/").PathSegments("users", userID, "orders")
+//
+// If a segment is empty, the rawURL is left unmodified and an error is
+// returned by Request (see Err).
+func (s *Sling) PathSegments(segments ...string) *Sling {
+	s.checkMutable()
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		if segment == "" {
+			s.addErr("PathSegments", strings.Join(segments, "/"), fmt.Errorf("empty segment %d", i))
+			return s
+		}
+		escaped[i] = escapePathSegment(segment)
+	}
+	return s.Path(strings.Join(escaped, "/"))
+}
+
+// escapePathSegment escapes segment as a single relative path segment,
+// including dot segments and colons, which could be taken for a scheme.
+func escapePathSegment(segment string) string {
+	if segment == "." || segment == ".." {
+		return strings.Repeat("%2E", len(segment))
+	}
+	return strings.ReplaceAll(url.PathEscape(segment), ":", "%3A")
+}
+
 // QueryStruct appends the queryStruct to the Sling's queryStructs. The value
 // pointed to by each queryStruct will be encoded as url query parameters on
 // new requests (see Request()).
 // The queryStruct argument should be a pointer to a url tagged struct. See
 // https://godoc.org/github.com/google/go-querystring/query for details.
+// Values which aren't structs are ignored and an error is returned by
+// Request (see Err).
 func (s *Sling) QueryStruct(queryStruct interface{}) *Sling {
-	if queryStruct != nil {
-		s.queryStructs = append(s.queryStructs, queryStruct)
+	s.checkMutable()
+	if queryStruct == nil {
+		return s
 	}
+	if !isStruct(queryStruct) {
+		s.addErr("QueryStruct", fmt.Sprintf("%T", queryStruct), errors.New("not a struct"))
+		return s
+	}
+	s.queryStructs = append(s.queryStructs, queryStruct)
 	return s
 }
 
 func (s *Sling) QueryParams(params map[string]string) *Sling {
+	s.checkMutable()
 	if params != nil {
 		s.queryParams = params
 	}
 	return s
 }
 
+// queryEdit sets, adds or removes (replace with no values) the values of a
+// query parameter.
+type queryEdit struct {
+	key     string
+	values  []string
+	replace bool
+}
+
+// QueryParam sets the key query parameter of new requests to value (see
+// Request()), overriding any values of key from the URL, query structs,
+// QueryParams or earlier QueryParam and QueryValues calls, e.g. inherited
+// from a parent Sling. Use QueryValues to send repeated parameters.
+func (s *Sling) QueryParam(key, value string) *Sling {
+	s.checkMutable()
+	s.queryEdits = append(s.queryEdits, queryEdit{key: key, values: []string{value}, replace: true})
+	return s
+}
+
+// QueryValues adds the query parameters of values to new requests (see
+// Request()), including multi-valued ones, e.g. url.Values{"tag": {"a",
+// "b"}} encodes as "tag=a&tag=b". Values are appended to any values of the
+// same keys.
+func (s *Sling) QueryValues(values url.Values) *Sling {
+	s.checkMutable()
+	for key, v := range values {
+		s.queryEdits = append(s.queryEdits, queryEdit{key: key, values: append([]string(nil), v...)})
+	}
+	return s
+}
+
+// RemoveQueryParam removes the key query parameter from new requests (see
+// Request()), whether it comes from the URL, query structs, QueryParams or
+// earlier QueryParam and QueryValues calls, e.g. inherited from a parent
+// Sling.
+func (s *Sling) RemoveQueryParam(key string) *Sling {
+	s.checkMutable()
+	s.queryEdits = append(s.queryEdits, queryEdit{key: key, replace: true})
+	return s
+}
+
+// CacheBust sets the paramName query parameter to a unique timestamp based
+// nonce each time a request is created (see Request()), so responses can't be
+// served from intermediary caches. An empty paramName disables cache busting.
+func (s *Sling) CacheBust(paramName string) *Sling {
+	s.checkMutable()
+	s.cacheBustParam = paramName
+	return s
+}
+
+// cacheBustSeq disambiguates cache bust nonces created within the same clock
+// tick.
+var cacheBustSeq uint64
+
+// cacheBustNonce returns a unique value for cache busting query parameters.
+func cacheBustNonce() string {
+	seq := atomic.AddUint64(&cacheBustSeq, 1)
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + strconv.FormatUint(seq, 36)
+}
+
 // Body
 
 // Body sets the Sling's body. The body value will be set as the Body on new
@@ -309,10 +773,12 @@ func (s *Sling) Body(body io.Reader) *Sling {
 
 // BodyProvider sets the Sling's body provider.
 func (s *Sling) BodyProvider(body BodyProvider) *Sling {
+	s.checkMutable()
 	if body == nil {
 		return s
 	}
 	s.bodyProvider = body
+	s.protocol = nil
 
 	ct := body.ContentType()
 	if ct != "" {
@@ -333,59 +799,261 @@ func (s *Sling) BodyJSON(bodyJSON interface{}) *Sling {
 	return s.BodyProvider(jsonBodyProvider{payload: bodyJSON})
 }
 
+// BodyBytes sets the Sling's body to the raw payload b with the given
+// Content-Type (left unset if empty), e.g. pre-serialized JSON. The payload
+// is not copied and must not be modified while requests are built. A nil b
+// is ignored.
+func (s *Sling) BodyBytes(b []byte, contentType string) *Sling {
+	if b == nil {
+		return s
+	}
+	return s.BodyProvider(bytesBodyProvider{payload: b, contentType: contentType})
+}
+
+// BodyString sets the Sling's body to the raw payload body with the given
+// Content-Type (left unset if empty), e.g. plain text.
+func (s *Sling) BodyString(body, contentType string) *Sling {
+	return s.BodyProvider(bytesBodyProvider{payload: []byte(body), contentType: contentType})
+}
+
+// BodyFunc sets the Sling's body to the reader returned by fn, with the
+// returned Content-Type (left unset if empty). fn is called with the request
+// context by each Request and again whenever the body must be replayed
+// (retries, redirects), so bodies depending on fresh data (timestamps,
+// nonces, signatures) are generated at send time:
+//
+//	s.Post("events").BodyFunc(func(ctx context.Context) (io.Reader, string, error) {
+//		return strings.NewReader(signedEvent(time.Now())), "application/json", nil
+//	})
+//
+// A nil fn is ignored.
+func (s *Sling) BodyFunc(fn func(ctx context.Context) (io.Reader, string, error)) *Sling {
+	if fn == nil {
+		return s
+	}
+	return s.BodyProvider(funcBodyProvider{fn: fn})
+}
+
+// BodyStream sets the Sling's body to the reader returned by open, streamed
+// with chunked transfer encoding so that large uploads are never buffered,
+// with the given Content-Type (left unset if empty). open is called for each
+// new request and again whenever the body must be replayed (retries,
+// redirects, see http.Request.GetBody), so it must return the same content
+// from its start each time:
+//
+//	s.Put("backups/db.tar").BodyStream(func() (io.ReadCloser, error) {
+//		return os.Open("/var/backups/db.tar")
+//	}, "application/x-tar")
+//
+// Use BodyFile for files whose size should be sent as Content-Length. A nil
+// open is ignored.
+func (s *Sling) BodyStream(open func() (io.ReadCloser, error), contentType string) *Sling {
+	if open == nil {
+		return s
+	}
+	return s.BodyProvider(streamBodyProvider{open: open, contentType: contentType})
+}
+
+// BodyCBOR sets the Sling's body to bodyCBOR encoded as CBOR with
+// Content-Type application/cbor on new requests. Struct fields are named
+// from their cbor tags, falling back to json tags. Use CborDecoder to decode
+// CBOR responses.
+func (s *Sling) BodyCBOR(bodyCBOR interface{}) *Sling {
+	if bodyCBOR == nil {
+		return s
+	}
+	return s.BodyProvider(cborBodyProvider{payload: bodyCBOR})
+}
+
+// BodyYAML sets the Sling's body to bodyYAML encoded as YAML with
+// Content-Type application/yaml on new requests. The bodyYAML argument
+// should be a yaml tagged struct, see
+// https://pkg.go.dev/gopkg.in/yaml.v3#Marshal for details. Use YamlDecoder
+// to decode YAML responses.
+func (s *Sling) BodyYAML(bodyYAML interface{}) *Sling {
+	if bodyYAML == nil {
+		return s
+	}
+	return s.BodyProvider(yamlBodyProvider{payload: bodyYAML})
+}
+
 // BodyForm sets the Sling's bodyForm. The value pointed to by the bodyForm
 // will be url encoded as the Body on new requests (see Request()).
 // The bodyForm argument should be a pointer to a url tagged struct. See
 // https://godoc.org/github.com/google/go-querystring/query for details.
+// Structs with fields of type FileField, *os.File or io.Reader are encoded
+// as multipart/form-data instead, with each non-nil file field sent as a
+// file part.
 func (s *Sling) BodyForm(bodyForm interface{}) *Sling {
 	if bodyForm == nil {
 		return s
 	}
+	if hasFileFields(bodyForm) {
+		return s.BodyProvider(multipartFormBodyProvider{payload: bodyForm, boundary: randomBoundary()})
+	}
 	return s.BodyProvider(formBodyProvider{payload: bodyForm})
 }
 
+// BodyFormValues sets the Sling's body to the url encoded values, sent as
+// application/x-www-form-urlencoded. The values are encoded on new requests
+// (see Request()). Nil values are ignored.
+func (s *Sling) BodyFormValues(values url.Values) *Sling {
+	if values == nil {
+		return s
+	}
+	return s.BodyProvider(formValuesBodyProvider{values: values})
+}
+
+// BodyFormMap sets the Sling's body to the url encoded key value pairs of
+// form, see BodyFormValues. A nil form is ignored.
+func (s *Sling) BodyFormMap(form map[string]string) *Sling {
+	if form == nil {
+		return s
+	}
+	values := make(url.Values, len(form))
+	for key, value := range form {
+		values.Set(key, value)
+	}
+	return s.BodyFormValues(values)
+}
+
+// BodyFile sets the Sling's body to the file at path, streamed on new
+// requests without reading it into memory. The Content-Length is set from
+// the file size and the Content-Type from the file extension, defaulting to
+// application/octet-stream. Requests are given a GetBody reopening the
+// file, so it is sent again on redirects and retries (see AutoRetry).
+// Errors opening the file are returned when the request is created.
+func (s *Sling) BodyFile(path string) *Sling {
+	if path == "" {
+		return s
+	}
+	return s.BodyProvider(fileBodyProvider{path: path})
+}
+
+// BodyProto sets the Sling's body to msg, encoded as binary protobuf with
+// Content-Type application/x-protobuf on new requests. Use ProtoDecoder to
+// decode protobuf responses:
+//
+//	s.Post("twirp/pkg.Service/Method").BodyProto(req).ResponseDecoder(sling.ProtoDecoder{})
+func (s *Sling) BodyProto(msg proto.Message) *Sling {
+	if msg == nil {
+		return s
+	}
+	return s.BodyProvider(protoBodyProvider{payload: msg})
+}
+
+// BodyMultipart sets the Sling's body to a new multipart/form-data body and
+// returns it for adding fields and files, e.g.
+//
+//	s.BodyMultipart().AddField("name", "gopher").AddFile("avatar", "avatar.png")
+//	resp, err := s.Receive(user, apiErr)
+//
+// The body is shared with Slings derived with New(), which see parts added
+// later.
+func (s *Sling) BodyMultipart() *MultipartBody {
+	body := NewMultipartBody()
+	s.BodyProvider(body)
+	return body
+}
+
 // Requests
 
 // Request returns a new http.Request created with the Sling properties.
 // Returns any errors parsing the rawURL, encoding query structs, encoding
 // the body, or creating the http.Request.
 func (s *Sling) Request() (*http.Request, error) {
-	reqURL, err := url.Parse(s.rawURL)
-	if err != nil {
+	s.markUsed()
+	if err := s.Err(); err != nil {
 		return nil, err
 	}
-
-	err = buildQueryParamUrl(reqURL, s.queryStructs, s.queryParams)
+	reqURL, err := s.requestURL(true)
 	if err != nil {
 		return nil, err
 	}
 
 	var body io.Reader
+	var contentType string
 	if s.bodyProvider != nil {
-		body, err = s.bodyProvider.Body()
+		switch p := s.bodyProvider.(type) {
+		case funcBodyProvider:
+			body, contentType, err = p.bodyContext(s.Context())
+		case formBodyProvider:
+			p.timeFormat = s.timeFormat
+			body, err = p.Body()
+		default:
+			body, err = s.bodyProvider.Body()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.newRequest(s.Context(), reqURL.String(), body, contentType)
+}
+
+// newRequest returns a new http.Request of the Sling to rawURL with body,
+// compressed if the Sling sets a BodyEncoding, and the headers and cookies
+// of the Sling. A non-empty contentType overrides the Content-Type header.
+// Bodies are encrypted last, and closed if the request can't be built. It is
+// shared by Request and PreparedRequest.
+func (s *Sling) newRequest(ctx context.Context, rawURL string, body io.Reader, contentType string) (_ *http.Request, err error) {
+	defer func() {
 		if err != nil {
+			closeBody(body, err)
+		}
+	}()
+	if s.bodyEncoding != "" && body != nil {
+		if body, err = compressBody(body); err != nil {
 			return nil, err
 		}
 	}
-	req, err := http.NewRequestWithContext(s.Context(), s.method, reqURL.String(), body)
+	req, err := http.NewRequestWithContext(ctx, s.method, rawURL, body)
 	if err != nil {
 		return nil, err
 	}
+	if rewindable, ok := body.(*rewindableBody); ok {
+		req.GetBody = rewindable.getBody
+	}
+	if file, ok := body.(*fileBody); ok {
+		req.ContentLength, req.GetBody = file.size, file.getBody
+		if file.size == 0 {
+			file.Close()
+			req.Body = http.NoBody
+		}
+	}
 	addHeaders(req, s.header)
-	return req, err
+	if err := setHeaderStructs(req, s.headerStructs); err != nil {
+		return nil, err
+	}
+	s.setHeaderFuncs(req)
+	setDefaultUserAgent(req)
+	for _, cookie := range s.cookies {
+		req.AddCookie(cookie)
+	}
+	if contentType != "" {
+		req.Header.Set(hdrContentTypeKey, contentType)
+	}
+	if s.bodyEncoding != "" && body != nil {
+		req.Header.Set("Content-Encoding", string(s.bodyEncoding))
+	}
+	if s.encrypter != nil {
+		if err := encryptBody(req, s.encrypter); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
 }
 
 // buildQueryParamUrl parses url tagged query structs using go-querystring to
 // encode them to url.Values and format them onto the url.RawQuery. Any
 // query parsing or encoding errors are returned.
-func buildQueryParamUrl(reqURL *url.URL, queryStructs []interface{}, queryParams map[string]string) error {
+func buildQueryParamUrl(reqURL *url.URL, queryStructs []interface{}, queryParams map[string]string, queryEdits []queryEdit, timeFormat *timeFormat) error {
 	urlValues, err := url.ParseQuery(reqURL.RawQuery)
 	if err != nil {
 		return err
 	}
 	// encodes query structs into a url.Values map and merges maps
 	for _, queryStruct := range queryStructs {
-		queryValues, err := goquery.Values(queryStruct)
+		queryValues, err := encodeValues(queryStruct, timeFormat)
 		if err != nil {
 			return err
 		}
@@ -398,6 +1066,14 @@ func buildQueryParamUrl(reqURL *url.URL, queryStructs []interface{}, queryParams
 	for k, v := range queryParams {
 		urlValues.Add(k, v)
 	}
+	for _, edit := range queryEdits {
+		if edit.replace {
+			urlValues.Del(edit.key)
+		}
+		for _, value := range edit.values {
+			urlValues.Add(edit.key, value)
+		}
+	}
 	// url.Values format to a sorted "url encoded" string, e.g. "key=val&foo=bar"
 	reqURL.RawQuery = urlValues.Encode()
 	return nil
@@ -417,6 +1093,7 @@ func addHeaders(req *http.Request, header http.Header) {
 
 // ResponseDecoder sets the Sling's response decoder.
 func (s *Sling) ResponseDecoder(decoder ResponseDecoder) *Sling {
+	s.checkMutable()
 	if decoder == nil {
 		return s
 	}
@@ -424,78 +1101,309 @@ func (s *Sling) ResponseDecoder(decoder ResponseDecoder) *Sling {
 	return s
 }
 
+// TransformResponse appends a transformer which is run after a success
+// response has been decoded. When transformers are set, the response is first
+// decoded into a generic value, then passed through each transformer in order
+// and the final value is stored into successV. For example,
+//
+//	unwrapData := func(v interface{}) (interface{}, error) {
+//		return v.(map[string]interface{})["data"], nil
+//	}
+//	resp, err := sling.New().Get(url).TransformResponse(unwrapData).ReceiveSuccess(user)
+//
+// The generic value is decoded with the ResponseDecoder, so transformers
+// work with JSON, CBOR, YAML or MessagePack bodies, but not with the
+// protocol buffer decoders (JsonpbDecoder, ProtoDecoder), for which
+// receiving fails with an error.
+func (s *Sling) TransformResponse(transformer ResponseTransformer) *Sling {
+	s.checkMutable()
+	if transformer == nil {
+		return s
+	}
+	s.transformers = append(s.transformers, transformer)
+	return s
+}
+
 // ReceiveSuccess creates a new HTTP request and returns the response. Success
 // responses (2XX) are JSON decoded into the value pointed to by successV.
 // Any error creating the request, sending it, or decoding a 2XX response
-// is returned.
-func (s *Sling) ReceiveSuccess(successV interface{}) (*Response, error) {
-	return s.Receive(successV, nil)
+// is returned. Settings can be overridden for this call only with
+// PerRequest options.
+func (s *Sling) ReceiveSuccess(successV interface{}, opts ...PerRequest) (*Response, error) {
+	return s.Receive(successV, nil, opts...)
 }
 
 // Receive creates a new HTTP request and returns the response. Success
 // responses (2XX) are JSON decoded into the value pointed to by successV and
 // other responses are JSON decoded into the value pointed to by failureV.
-// If the status code of response is 204(no content) or the Content-Lenght is 0,
-// decoding is skipped. Any error creating the request, sending it, or decoding
-// the response is returned.
+// If the status code of response is 204(no content), 304(not modified) or the
+// Content-Length is 0, decoding is skipped. Any error creating the request,
+// sending it, or decoding the response is returned. Settings can be
+// overridden for this call only with PerRequest options.
 // Receive is shorthand for calling Request and Do.
-func (s *Sling) Receive(successV, failureV interface{}) (*Response, error) {
+func (s *Sling) Receive(successV, failureV interface{}, opts ...PerRequest) (*Response, error) {
 	req, err := s.Request()
 	if err != nil {
 		return nil, err
 	}
-	return s.Do(req, successV, failureV)
+	return s.Do(req, successV, failureV, opts...)
 }
 
 // Do sends an HTTP request and returns the response. Success responses (2XX)
 // are JSON decoded into the value pointed to by successV and other responses
 // are JSON decoded into the value pointed to by failureV.
-// If the status code of response is 204(no content) or the Content-Length is 0,
-// decoding is skipped. Any error sending the request or decoding the response
-// is returned.
-func (s *Sling) Do(req *http.Request, successV, failureV interface{}) (*Response, error) {
-	resp, rawData, err := s.httpClient.Do(req)
+// If the status code of response is 204(no content), 304(not modified) or the
+// Content-Length is 0, decoding is skipped. Any error sending the request or
+// decoding the response is returned; failure responses which cannot be
+// decoded return a *FailureDecodeError. Settings can be overridden for this
+// call only with PerRequest options.
+func (s *Sling) Do(req *http.Request, successV, failureV interface{}, opts ...PerRequest) (*Response, error) {
+	if len(s.decodingLayer) > 0 {
+		decode := func(req *http.Request, successV, failureV interface{}) (*Response, error) {
+			return s.doLayer(req, successV, failureV, opts)
+		}
+		return s.decodingDoer(decode).DoDecode(req, successV, failureV)
+	}
+	return s.doLayer(req, successV, failureV, opts)
+}
+
+// doLayer sends req and decodes its response, below the decoding
+// middlewares.
+func (s *Sling) doLayer(req *http.Request, successV, failureV interface{}, opts []PerRequest) (response *Response, err error) {
+	s.profiled(req, func(req *http.Request) {
+		response, err = s.do(req, successV, failureV, opts)
+	})
+	return response, err
+}
+
+func (s *Sling) do(req *http.Request, successV, failureV interface{}, opts []PerRequest) (*Response, error) {
+	response, decode, err := s.send(req, successV, failureV, opts)
+	if err != nil || decode == nil {
+		return response, err
+	}
+	return response, decode()
+}
+
+// send sends req and returns the response with a function decoding it into
+// successV or failureV, nil when there is nothing to decode. The decode
+// function releases the resources of the call and must be called.
+func (s *Sling) send(req *http.Request, successV, failureV interface{}, opts []PerRequest) (*Response, func() error, error) {
+	s.markUsed()
+	s.setSpanAttributes(req.Context())
+	if s.tenantKey != nil {
+		if tenant := s.tenantKey(req.Context()); tenant != "" {
+			req = req.WithContext(context.WithValue(req.Context(), tenantKey{}, tenant))
+		}
+	}
+	if len(s.apiKeys) > 0 {
+		req = req.WithContext(context.WithValue(req.Context(), apiKeysKey{}, s.apiKeys))
+	}
+	memo := s.memo
+	var cacheKey memoKey
+	if len(opts) > 0 {
+		memo = nil
+	}
+	if memo != nil {
+		if s.bearerToken != nil {
+			// the token is part of the key, and reused by bearerDoer
+			token, err := s.bearerToken(req.Context())
+			if err != nil {
+				return nil, nil, err
+			}
+			req = req.WithContext(context.WithValue(req.Context(), bearerTokenKey{}, token))
+		}
+		var ok bool
+		if cacheKey, ok = memo.key(req, successV); !ok {
+			memo = nil
+		} else if response, ok := memo.load(cacheKey, successV); ok {
+			AddSpanEvent(req.Context(), SpanEventCacheHit)
+			return response, nil, nil
+		}
+	}
+	var cleanup []func()
+	release := func() {
+		for _, f := range cleanup {
+			f()
+		}
+	}
+	decoder, isSuccess := s.responseDecoder, s.isSuccess
+	if len(opts) > 0 {
+		override := mergePerRequest(opts)
+		ctx := req.Context()
+		if override.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = withTimeoutReason(ctx, override.Timeout, CancelTimeout)
+			cleanup = append(cleanup, cancel)
+		}
+		if override.RetryPolicy != nil {
+			ctx = contextWithRetryPolicy(ctx, override.RetryPolicy)
+		}
+		req = req.WithContext(ctx)
+		if override.Decoder != nil {
+			decoder = override.Decoder
+		}
+		if override.SuccessDecider != nil {
+			isSuccess = override.SuccessDecider
+		}
+	}
+
+	if s.adaptiveTimeout != nil {
+		var cancel context.CancelFunc
+		req, cancel = s.adaptiveTimeout.withTimeout(req)
+		cleanup = append(cleanup, cancel)
+	}
+	req = s.withDeadlineHint(req)
+	if s.redactor != nil {
+		req = req.WithContext(context.WithValue(req.Context(), redactorKey{}, s.redactor))
+	}
+	if _, ok := successV.(streamReceiver); ok {
+		req = req.WithContext(withStreaming(req.Context()))
+	}
+	if s.decompress != nil {
+		req = s.decompress.negotiate(req)
+	}
+	if s.progress != nil {
+		req = req.WithContext(context.WithValue(req.Context(), progressKey{}, s.progress))
+	}
+
+	start := time.Now()
+	resp, rawData, err := s.doer().Do(req)
+	err = withCancellationReason(req.Context(), err)
+	if s.adaptiveTimeout != nil && err == nil {
+		s.adaptiveTimeout.observe(req, time.Since(start))
+	}
+	if s.endpoints != nil {
+		s.endpoints.observe(req, resp, err, time.Since(start))
+	}
+	response := NewResponse(resp, rawData)
+	response.redactor = s.redactor
 	if err != nil {
-		return NewResponse(resp, rawData), err
+		release()
+		return response, nil, err
+	}
+	if stream := response.stream; stream != nil {
+		cleanup = append(cleanup, func() { stream.Close() })
+		if !isSuccess(resp) {
+			if err := response.buffer(); err != nil {
+				release()
+				return response, nil, err
+			}
+		}
+	}
+	if s.decompress != nil && response.stream == nil {
+		threshold, dir := spillSettings(s.httpClient)
+		if err := response.decompress(req, s.decompress, threshold, dir); err != nil {
+			release()
+			return response, nil, err
+		}
+	}
+	if s.clockSkew != nil {
+		s.clockSkew.check(req, response)
+	}
+	if s.onDeprecation != nil {
+		if d, ok := response.Deprecation(); ok {
+			s.onDeprecation(req, d)
+		}
+	}
+	if s.verifier != nil {
+		if err := s.verifier.verify(response); err != nil {
+			release()
+			return response, nil, err
+		}
+	}
+	if s.decrypter != nil {
+		if err := response.decrypt(s.decrypter); err != nil {
+			release()
+			return response, nil, err
+		}
+	}
+
+	if s.tee != nil {
+		if err := response.teeBody(s.tee); err != nil {
+			release()
+			return response, nil, err
+		}
+	}
+	if s.grpcStatus {
+		if err := response.GrpcStatus(); err != nil {
+			release()
+			return response, nil, err
+		}
 	}
 
-	// Don't try to decode on 204s or Content-Length is 0
-	if resp.StatusCode == http.StatusNoContent || resp.ContentLength == 0 {
-		return NewResponse(resp, rawData), nil
+	// Don't try to decode on 204s, 304s or Content-Length is 0
+	if resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified || resp.ContentLength == 0 {
+		release()
+		return response, nil, nil
+	}
+	if successV == nil && failureV == nil && s.protocol == nil {
+		release()
+		return response, nil, nil
 	}
 
-	// Decode from json
-	if successV != nil || failureV != nil {
-		err = decodeResponse(resp, rawData, s.isSuccess, s.responseDecoder, successV, failureV)
+	decode := func() error {
+		defer release()
+		var err error
+		switch {
+		case s.protocol != nil:
+			err = s.protocol.decodeResponse(req.Context(), response, isSuccess, decoder, s.transformers, successV, failureV)
+		case s.envelope != nil:
+			err = s.envelope.decodeResponse(req.Context(), response, isSuccess, decoder, s.transformers, successV, failureV)
+		default:
+			err = decodeResponse(req.Context(), response, isSuccess, decoder, s.transformers, successV, failureV)
+		}
+		err = withCancellationReason(req.Context(), err)
+		if err == nil && memo != nil && isSuccess(resp) {
+			memo.store(cacheKey, req.URL, response, successV)
+		}
+		return err
 	}
-	return NewResponse(resp, rawData), err
+	return response, decode, nil
 }
 
 // decodeResponse decodes response Body into the value pointed to by successV
 // if the response is a success (2XX) or into the value pointed to by failureV
 // otherwise. If the successV or failureV argument to decode into is nil,
-// decoding is skipped.
+// decoding is skipped. Decoding of large bodies stops with the context error
+// once ctx is done, see Response.decode.
 // Caller is responsible for closing the resp.Body.
-func decodeResponse(resp *http.Response, rawData []byte, isSuccess SuccessDecider, decoder ResponseDecoder, successV, failureV interface{}) error {
-	if isSuccess(resp) {
+func decodeResponse(ctx context.Context, resp *Response, isSuccess SuccessDecider, decoder ResponseDecoder, transformers []ResponseTransformer, successV, failureV interface{}) error {
+	if isSuccess(resp.Response) {
 		switch sv := successV.(type) {
 		case nil:
 			return nil
 		case *Raw:
-			*sv = rawData
-			return nil
+			data, err := resp.bytes()
+			*sv = data
+			return err
+		case streamReceiver:
+			return sv.receive(ctx, resp.Body)
 		default:
-			return decoder.Decode(rawData, successV)
+			if len(transformers) > 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				data, err := resp.bytes()
+				if err != nil {
+					return err
+				}
+				return transformResponse(data, decoder, transformers, successV)
+			}
+			return resp.decode(ctx, decoder, successV)
 		}
 	} else {
 		switch fv := failureV.(type) {
 		case nil:
 			return nil
 		case *Raw:
-			*fv = rawData
-			return nil
+			data, err := resp.bytes()
+			*fv = data
+			return err
 		default:
-			return decoder.Decode(rawData, failureV)
+			if err := resp.decode(ctx, decoder, failureV); err != nil {
+				return &FailureDecodeError{StatusError: newStatusError(resp), Err: err}
+			}
+			return nil
 		}
 	}
 }
@@ -0,0 +1,61 @@
+// Package slingmsgpack encodes sling request bodies and decodes responses as
+// MessagePack (application/msgpack). It lives in its own package so that
+// sling does not depend on a MessagePack implementation.
+//
+//	api := sling.New().Base("http://users.internal/").
+//		Set("Accept", slingmsgpack.ContentType).
+//		ResponseDecoder(slingmsgpack.Decoder{})
+//	resp, err := api.New().Post("users").BodyProvider(slingmsgpack.Body(user)).ReceiveSuccess(created)
+//
+// Values are encoded with github.com/vmihailenco/msgpack/v5, which reads
+// `msgpack` struct tags and falls back to field names.
+package slingmsgpack
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/anhdhbn/sling"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ContentType is the MessagePack media type.
+const ContentType = "application/msgpack"
+
+// Body returns a sling.BodyProvider encoding v as MessagePack.
+func Body(v interface{}) sling.BodyProvider {
+	return bodyProvider{payload: v}
+}
+
+type bodyProvider struct {
+	payload interface{}
+}
+
+func (p bodyProvider) ContentType() string {
+	return ContentType
+}
+
+func (p bodyProvider) Body() (io.Reader, error) {
+	b, err := msgpack.Marshal(p.payload)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
+
+// Decoder decodes MessagePack responses into the value pointed to by v.
+type Decoder struct {
+}
+
+var _ sling.ReaderDecoder = Decoder{}
+
+// Decode decodes the Response Body into the value pointed to by v.
+func (d Decoder) Decode(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+// DecodeReader decodes the MessagePack read from r into the value pointed to
+// by v.
+func (d Decoder) DecodeReader(r io.Reader, v interface{}) error {
+	return msgpack.NewDecoder(r).Decode(v)
+}
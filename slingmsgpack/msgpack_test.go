@@ -0,0 +1,54 @@
+package slingmsgpack
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/anhdhbn/sling"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type user struct {
+	Name  string   `msgpack:"name"`
+	Age   int      `msgpack:"age"`
+	Roles []string `msgpack:"roles"`
+}
+
+func TestBodyAndDecoder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != ContentType {
+			t.Errorf("expected Content-Type %s, got %s", ContentType, ct)
+		}
+		b, _ := io.ReadAll(r.Body)
+		var in user
+		if err := msgpack.Unmarshal(b, &in); err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+		in.Roles = append(in.Roles, "admin")
+		out, _ := msgpack.Marshal(in)
+		w.Header().Set("Content-Type", ContentType)
+		w.Write(out)
+	}))
+	defer server.Close()
+
+	created := new(user)
+	_, err := sling.New().Post(server.URL).BodyProvider(Body(user{Name: "gopher", Age: 14})).
+		ResponseDecoder(Decoder{}).ReceiveSuccess(created)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	expected := &user{Name: "gopher", Age: 14, Roles: []string{"admin"}}
+	if !reflect.DeepEqual(expected, created) {
+		t.Errorf("expected %v, got %v", expected, created)
+	}
+}
+
+func TestDecoder_invalid(t *testing.T) {
+	var u user
+	if err := (Decoder{}).Decode([]byte{0xc1}, &u); err == nil {
+		t.Errorf("expected error decoding invalid MessagePack")
+	}
+}
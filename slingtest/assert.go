@@ -0,0 +1,141 @@
+package slingtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/anhdhbn/sling"
+)
+
+// UpdateGoldenEnv is the environment variable which, when set to a non
+// empty value, makes BodyMatchesGolden write golden files instead of
+// comparing them, e.g. SLINGTEST_UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "SLINGTEST_UPDATE_GOLDEN"
+
+// Assertions check a response, reporting each failure as a test error so
+// that table-driven tests read declaratively. Create them with Assert.
+type Assertions struct {
+	t    testing.TB
+	resp *sling.Response
+}
+
+// Assert returns the Assertions of resp, reported to t:
+//
+//	resp, err := srv.Sling().Get("users/1").ReceiveSuccess(nil)
+//	slingtest.Assert(t, resp).
+//		StatusIs(200).
+//		HeaderEquals("Content-Type", "application/json").
+//		JSONPathEquals("name", "gopher").
+//		BodyMatchesGolden("testdata/user.golden.json")
+func Assert(t testing.TB, resp *sling.Response) *Assertions {
+	t.Helper()
+	if resp == nil || resp.Response == nil {
+		t.Fatal("slingtest: no response to assert")
+	}
+	return &Assertions{t: t, resp: resp}
+}
+
+// StatusIs asserts the status code of the response.
+func (a *Assertions) StatusIs(status int) *Assertions {
+	a.t.Helper()
+	if a.resp.StatusCode != status {
+		a.t.Errorf("status: expected %d, got %d", status, a.resp.StatusCode)
+	}
+	return a
+}
+
+// HeaderEquals asserts the first value of the key header of the response.
+func (a *Assertions) HeaderEquals(key, value string) *Assertions {
+	a.t.Helper()
+	if got := a.resp.Header.Get(key); got != value {
+		a.t.Errorf("header %s: expected %q, got %q", key, value, got)
+	}
+	return a
+}
+
+// JSONPathEquals asserts the value at path of the JSON body (see
+// sling.Response.GetPath). expected is compared after a JSON round trip, so
+// Go numbers, structs and maps compare with their decoded form.
+func (a *Assertions) JSONPathEquals(path string, expected interface{}) *Assertions {
+	a.t.Helper()
+	got, err := a.resp.GetPath(path)
+	if err != nil {
+		a.t.Errorf("%s: %v", path, err)
+		return a
+	}
+	b, err := json.Marshal(expected)
+	if err != nil {
+		a.t.Errorf("%s: %v", path, err)
+		return a
+	}
+	var want interface{}
+	json.Unmarshal(b, &want)
+	if !reflect.DeepEqual(want, got) {
+		a.t.Errorf("%s: expected %s, got %s", path, b, mustMarshal(got))
+	}
+	return a
+}
+
+// BodyMatchesGolden asserts the body of the response equals the contents
+// of the golden file. JSON bodies are compared semantically and written
+// indented. Golden files are written instead when UpdateGoldenEnv is set.
+func (a *Assertions) BodyMatchesGolden(file string) *Assertions {
+	a.t.Helper()
+	reader := a.resp.Reader()
+	body, err := io.ReadAll(reader)
+	a.resp.ResetBody()
+	if err != nil {
+		a.t.Errorf("body: %v", err)
+		return a
+	}
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if indented, ok := indentJSON(body); ok {
+			body = indented
+		}
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err == nil {
+			err = os.WriteFile(file, body, 0o644)
+		}
+		if err != nil {
+			a.t.Errorf("golden %s: %v", file, err)
+		}
+		return a
+	}
+	golden, err := os.ReadFile(file)
+	if err != nil {
+		a.t.Errorf("golden %s: %v (set %s=1 to create it)", file, err, UpdateGoldenEnv)
+		return a
+	}
+	gotJSON, gotOK := indentJSON(body)
+	wantJSON, wantOK := indentJSON(golden)
+	if gotOK && wantOK {
+		body, golden = gotJSON, wantJSON
+	}
+	if !bytes.Equal(body, golden) {
+		a.t.Errorf("body does not match golden %s:\nexpected:\n%s\ngot:\n%s", file, golden, body)
+	}
+	return a
+}
+
+// indentJSON returns the canonical indented form of a JSON document,
+// reporting false if b is not JSON.
+func indentJSON(b []byte) ([]byte, bool) {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, false
+	}
+	indented, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, false
+	}
+	return append(indented, '\n'), true
+}
+
+func mustMarshal(v interface{}) []byte {
+	b, _ := json.Marshal(v)
+	return b
+}
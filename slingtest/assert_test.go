@@ -0,0 +1,69 @@
+package slingtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recorder records the errors of assertions expected to fail.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssert(t *testing.T) {
+	srv := NewServer(t)
+	srv.On("GET", "/users/1").ReplyHeader("X-Request-Id", "abc").
+		ReplyJSON(200, map[string]interface{}{"id": 1, "name": "gopher", "tags": []string{"a", "b"}})
+	resp, err := srv.Sling().Get("users/1").ReceiveSuccess(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join(t.TempDir(), "user.golden.json")
+	os.WriteFile(golden, []byte(`{"tags": ["a", "b"], "name": "gopher", "id": 1}`), 0o644)
+
+	Assert(t, resp).
+		StatusIs(200).
+		HeaderEquals("X-Request-Id", "abc").
+		JSONPathEquals("id", 1).
+		JSONPathEquals("tags", []string{"a", "b"}).
+		BodyMatchesGolden(golden)
+
+	rec := &recorder{TB: t}
+	Assert(rec, resp).
+		StatusIs(404).
+		HeaderEquals("X-Request-Id", "xyz").
+		JSONPathEquals("name", "other").
+		JSONPathEquals("missing", 1).
+		BodyMatchesGolden(filepath.Join(t.TempDir(), "missing.json"))
+	expected := []string{"status: expected 404", "header X-Request-Id", `name: expected "other"`, "missing:", "golden"}
+	if len(rec.errors) != len(expected) {
+		t.Fatalf("expected %d errors, got %q", len(expected), rec.errors)
+	}
+	for i, prefix := range expected {
+		if !strings.HasPrefix(rec.errors[i], prefix) {
+			t.Errorf("expected error starting with %q, got %q", prefix, rec.errors[i])
+		}
+	}
+}
+
+func TestAssert_updateGolden(t *testing.T) {
+	srv := NewServer(t)
+	srv.On("GET", "/users/1").ReplyJSON(200, map[string]interface{}{"id": 1})
+	resp, _ := srv.Sling().Get("users/1").ReceiveSuccess(nil)
+	golden := filepath.Join(t.TempDir(), "testdata", "user.json")
+	t.Setenv(UpdateGoldenEnv, "1")
+	Assert(t, resp).BodyMatchesGolden(golden)
+	if b, err := os.ReadFile(golden); err != nil || string(b) != "{\n  \"id\": 1\n}\n" {
+		t.Errorf("expected indented golden file, got %q, %v", b, err)
+	}
+}
@@ -0,0 +1,146 @@
+package slingtest
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JWTKey is the HMAC key signing the tokens of JWT and verifying them in
+// ParseJWT.
+var JWTKey = []byte("slingtest")
+
+// ErrTokenExpired is returned by ParseJWT for tokens past their "exp" claim.
+var ErrTokenExpired = errors.New("slingtest: token expired")
+
+// Claims are the claims of a JSON Web Token.
+type Claims map[string]interface{}
+
+// JWT returns an HS256 JSON Web Token with the given claims, signed with
+// JWTKey. Unless expiresAt is zero, the "exp" claim is set to it, so tokens
+// which expire during a test or are already expired can be fabricated.
+//
+//	token := slingtest.JWT(slingtest.Claims{"sub": "gopher"}, time.Now().Add(time.Minute))
+func JWT(claims Claims, expiresAt time.Time) string {
+	payload := make(Claims, len(claims)+1)
+	for k, v := range claims {
+		payload[k] = v
+	}
+	if !expiresAt.IsZero() {
+		payload["exp"] = expiresAt.Unix()
+	}
+	header := encodeSegment(map[string]string{"alg": "HS256", "typ": "JWT"})
+	unsigned := header + "." + encodeSegment(payload)
+	return unsigned + "." + signJWT(unsigned)
+}
+
+// ParseJWT verifies the signature of a token created by JWT and returns its
+// claims. Expired tokens return their claims with ErrTokenExpired. Bearer
+// prefixed values, as read from an Authorization header, are accepted.
+func ParseJWT(token string) (Claims, error) {
+	token = strings.TrimPrefix(token, "Bearer ")
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("slingtest: malformed token")
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(signJWT(parts[0]+"."+parts[1]))) {
+		return nil, errors.New("slingtest: invalid token signature")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("slingtest: malformed token: %v", err)
+	}
+	var claims Claims
+	if err := json.Unmarshal(b, &claims); err != nil {
+		return nil, fmt.Errorf("slingtest: malformed token: %v", err)
+	}
+	if exp, ok := claims["exp"].(float64); ok && time.Now().Unix() >= int64(exp) {
+		return claims, ErrTokenExpired
+	}
+	return claims, nil
+}
+
+func encodeSegment(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("slingtest: cannot encode token: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func signJWT(unsigned string) string {
+	mac := hmac.New(sha256.New, JWTKey)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Credentials is a fake credentials provider answering Token calls with a
+// script of tokens and failures, e.g. to test that a client refreshes an
+// expired token after a transient failure:
+//
+//	creds := new(slingtest.Credentials).
+//		ThenToken(slingtest.JWT(nil, time.Now().Add(-time.Minute))).
+//		ThenError(errors.New("auth server down")).
+//		ThenToken(slingtest.JWT(nil, time.Now().Add(time.Hour)))
+//
+// The zero value is ready to use.
+type Credentials struct {
+	mu    sync.Mutex
+	steps []credentialsStep
+	calls int
+}
+
+type credentialsStep struct {
+	token string
+	err   error
+}
+
+// ThenToken appends a step returning token.
+func (c *Credentials) ThenToken(token string) *Credentials {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.steps = append(c.steps, credentialsStep{token: token})
+	return c
+}
+
+// ThenError appends a step failing with err.
+func (c *Credentials) ThenError(err error) *Credentials {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.steps = append(c.steps, credentialsStep{err: err})
+	return c
+}
+
+// Token returns the token or error of the next step. Once the script is
+// exhausted its last step is repeated. Calls with a done ctx return its
+// error without consuming a step.
+func (c *Credentials) Token(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.steps) == 0 {
+		return "", errors.New("slingtest: no scripted credentials")
+	}
+	step := c.steps[len(c.steps)-1]
+	if c.calls < len(c.steps) {
+		step = c.steps[c.calls]
+	}
+	c.calls++
+	return step.token, step.err
+}
+
+// Calls returns the number of Token calls which consumed a step.
+func (c *Credentials) Calls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
@@ -0,0 +1,81 @@
+package slingtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJWT(t *testing.T) {
+	token := JWT(Claims{"sub": "gopher", "scope": "read"}, time.Now().Add(time.Minute))
+	claims, err := ParseJWT("Bearer " + token)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if claims["sub"] != "gopher" || claims["scope"] != "read" {
+		t.Errorf("expected sub and scope claims, got %v", claims)
+	}
+	if _, ok := claims["exp"]; !ok {
+		t.Errorf("expected exp claim, got %v", claims)
+	}
+
+	expired := JWT(Claims{"sub": "gopher"}, time.Now().Add(-time.Minute))
+	if claims, err := ParseJWT(expired); err != ErrTokenExpired || claims["sub"] != "gopher" {
+		t.Errorf("expected %v with claims, got %v, %v", ErrTokenExpired, claims, err)
+	}
+	if _, err := ParseJWT(JWT(nil, time.Time{})); err != nil {
+		t.Errorf("expected token without exp to be valid, got %v", err)
+	}
+
+	tampered := token[:len(token)-2] + "xx"
+	if _, err := ParseJWT(tampered); err == nil || err.Error() != "slingtest: invalid token signature" {
+		t.Errorf("expected invalid signature error, got %v", err)
+	}
+	if _, err := ParseJWT("abc"); err == nil || err.Error() != "slingtest: malformed token" {
+		t.Errorf("expected malformed token error, got %v", err)
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	ctx := context.Background()
+	var empty Credentials
+	if _, err := empty.Token(ctx); err == nil {
+		t.Errorf("expected error without script")
+	}
+
+	down := errors.New("auth server down")
+	creds := new(Credentials).ThenToken("a").ThenError(down).ThenToken("b")
+	cases := []struct {
+		token string
+		err   error
+	}{{"a", nil}, {"", down}, {"b", nil}, {"b", nil}}
+	for _, c := range cases {
+		token, err := creds.Token(ctx)
+		if token != c.token || err != c.err {
+			t.Errorf("expected %q, %v, got %q, %v", c.token, c.err, token, err)
+		}
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := creds.Token(canceled); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+	if creds.Calls() != 4 {
+		t.Errorf("expected 4 calls, got %d", creds.Calls())
+	}
+}
+
+func TestJWT_bearerRequest(t *testing.T) {
+	srv := NewServer(t)
+	srv.On("GET", "/me").Reply(200)
+	token := JWT(Claims{"sub": "gopher"}, time.Now().Add(time.Minute))
+	if _, err := srv.Sling().Get("me").SetBearerAuth(token).Receive(nil, nil); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	claims, err := ParseJWT(srv.Hits()[0].Header.Get("Authorization"))
+	if err != nil || claims["sub"] != "gopher" {
+		t.Errorf("expected sub claim, got %v, %v", claims, err)
+	}
+}
@@ -0,0 +1,279 @@
+package slingtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/anhdhbn/sling"
+)
+
+// Contract describes the expected shape of a recorded request or response.
+// Zero valued fields are not checked.
+type Contract struct {
+	// Method is the expected request method, only checked for requests.
+	Method string
+	// Status is the expected response status code, only checked for
+	// responses.
+	Status int
+	// ContentType is the expected media type, parameters such as charset are
+	// ignored.
+	ContentType string
+	// Schema is the expected JSON body shape.
+	Schema *Schema
+}
+
+// Violation is a single mismatch between a contract and a recorded message.
+type Violation struct {
+	// Path locates the mismatch, e.g. "status" or "$.items[0].id".
+	Path     string
+	Expected string
+	Got      string
+}
+
+func (v Violation) String() string {
+	if v.Got == "" {
+		return fmt.Sprintf("%s: expected %s", v.Path, v.Expected)
+	}
+	return fmt.Sprintf("%s: expected %s, got %s", v.Path, v.Expected, v.Got)
+}
+
+// ContractError is returned when a recorded message does not satisfy a
+// Contract. Its message lists every violation, one per line.
+type ContractError struct {
+	Violations []Violation
+}
+
+func (e *ContractError) Error() string {
+	lines := make([]string, 0, len(e.Violations)+1)
+	lines = append(lines, "contract violation:")
+	for _, v := range e.Violations {
+		lines = append(lines, "  "+v.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// VerifyResponse checks the status, content type and JSON body of resp
+// against the contract. It returns a *ContractError listing all violations.
+func (c Contract) VerifyResponse(resp *sling.Response) error {
+	if resp == nil || resp.Response == nil {
+		return &ContractError{Violations: []Violation{{Path: "response", Expected: "a response", Got: "nil"}}}
+	}
+	var violations []Violation
+	if c.Status != 0 && resp.StatusCode != c.Status {
+		violations = append(violations, Violation{
+			Path:     "status",
+			Expected: fmt.Sprint(c.Status),
+			Got:      fmt.Sprint(resp.StatusCode),
+		})
+	}
+	// spooled responses have no RawData, see sling.SpillToDisk
+	body, err := io.ReadAll(resp.Reader())
+	resp.ResetBody()
+	if err != nil {
+		return err
+	}
+	violations = append(violations, c.verifyMessage(resp.Header, body)...)
+	return violationsError(violations)
+}
+
+// VerifyRequest checks the method, content type and JSON body of req against
+// the contract. The request body is restored after being read.
+func (c Contract) VerifyRequest(req *http.Request) error {
+	var violations []Violation
+	if c.Method != "" && !strings.EqualFold(req.Method, c.Method) {
+		violations = append(violations, Violation{Path: "method", Expected: c.Method, Got: req.Method})
+	}
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	violations = append(violations, c.verifyMessage(req.Header, body)...)
+	return violationsError(violations)
+}
+
+func (c Contract) verifyMessage(header http.Header, body []byte) []Violation {
+	var violations []Violation
+	if c.ContentType != "" {
+		got := header.Get("Content-Type")
+		mediaType, _, err := mime.ParseMediaType(got)
+		if err != nil || !strings.EqualFold(mediaType, c.ContentType) {
+			violations = append(violations, Violation{Path: "content-type", Expected: c.ContentType, Got: quoted(got)})
+		}
+	}
+	if c.Schema != nil {
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			violations = append(violations, Violation{Path: "$", Expected: "a JSON body", Got: err.Error()})
+		} else {
+			violations = append(violations, c.Schema.validate("$", v)...)
+		}
+	}
+	return violations
+}
+
+func violationsError(violations []Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ContractError{Violations: violations}
+}
+
+func quoted(s string) string {
+	return fmt.Sprintf("%q", s)
+}
+
+// AssertResponse reports a test error listing every contract violation of
+// resp.
+func AssertResponse(t testing.TB, resp *sling.Response, c Contract) {
+	t.Helper()
+	if err := c.VerifyResponse(resp); err != nil {
+		t.Error(err)
+	}
+}
+
+// AssertRequest reports a test error listing every contract violation of
+// req.
+func AssertRequest(t testing.TB, req *http.Request, c Contract) {
+	t.Helper()
+	if err := c.VerifyRequest(req); err != nil {
+		t.Error(err)
+	}
+}
+
+// Schema is the subset of JSON Schema (and OpenAPI schema objects) used for
+// contract checks: type, nullable, required, properties, items and enum.
+type Schema struct {
+	Type       SchemaType         `json:"type,omitempty"`
+	Nullable   bool               `json:"nullable,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Enum       []interface{}      `json:"enum,omitempty"`
+	Ref        string             `json:"$ref,omitempty"`
+}
+
+// SchemaType lists the allowed JSON types of a Schema. It decodes from both
+// the "type": "string" and "type": ["string", "null"] forms.
+type SchemaType []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *SchemaType) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*t = SchemaType{single}
+		return nil
+	}
+	var multi []string
+	if err := json.Unmarshal(b, &multi); err != nil {
+		return err
+	}
+	*t = multi
+	return nil
+}
+
+// ParseSchema parses a JSON Schema document.
+func ParseSchema(b []byte) (*Schema, error) {
+	schema := new(Schema)
+	if err := json.Unmarshal(b, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (s *Schema) validate(path string, v interface{}) []Violation {
+	if s == nil {
+		return nil
+	}
+	got := jsonType(v)
+	if v == nil && s.Nullable {
+		return nil
+	}
+	if len(s.Type) > 0 && !s.allowsType(got) {
+		return []Violation{{Path: path, Expected: "type " + strings.Join(s.Type, " or "), Got: got}}
+	}
+	var violations []Violation
+	if len(s.Enum) > 0 && !inEnum(s.Enum, v) {
+		violations = append(violations, Violation{Path: path, Expected: fmt.Sprintf("one of %v", s.Enum), Got: fmt.Sprint(v)})
+	}
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := value[name]; !ok {
+				violations = append(violations, Violation{Path: path + "." + name, Expected: "required field"})
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if field, ok := value[name]; ok {
+				violations = append(violations, s.Properties[name].validate(path+"."+name, field)...)
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			violations = append(violations, s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item)...)
+		}
+	}
+	return violations
+}
+
+func (s *Schema) allowsType(got string) bool {
+	for _, t := range s.Type {
+		if t == got || (t == "number" && got == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type name of a value decoded with
+// json.Decoder.UseNumber.
+func jsonType(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func inEnum(enum []interface{}, v interface{}) bool {
+	got, _ := json.Marshal(v)
+	for _, e := range enum {
+		want, _ := json.Marshal(e)
+		if bytes.Equal(got, want) {
+			return true
+		}
+	}
+	return false
+}
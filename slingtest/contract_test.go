@@ -0,0 +1,161 @@
+package slingtest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/anhdhbn/sling"
+)
+
+const petstore = `{
+	"openapi": "3.0.0",
+	"paths": {
+		"/pets/{id}": {
+			"get": {
+				"responses": {
+					"200": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}
+				}
+			}
+		},
+		"/pets": {
+			"post": {
+				"requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+				"responses": {"default": {}}
+			}
+		}
+	},
+	"components": {
+		"schemas": {
+			"Pet": {
+				"type": "object",
+				"required": ["id", "name"],
+				"properties": {
+					"id": {"type": "integer"},
+					"name": {"type": "string"},
+					"status": {"type": "string", "enum": ["available", "sold"]},
+					"tags": {"type": "array", "items": {"type": "string"}},
+					"owner": {"$ref": "#/components/schemas/Pet"}
+				}
+			}
+		}
+	}
+}`
+
+func TestResponseContract(t *testing.T) {
+	cases := []struct {
+		status     int
+		body       string
+		violations []Violation
+	}{
+		{200, `{"id": 1, "name": "rex", "status": "sold", "tags": ["a"]}`, nil},
+		{404, `{"id": 1, "name": "rex"}`, []Violation{{Path: "status", Expected: "200", Got: "404"}}},
+		{200, `{"id": "1", "tags": ["a", 2], "status": "lost"}`, []Violation{
+			{Path: "$.name", Expected: "required field"},
+			{Path: "$.id", Expected: "type integer", Got: "string"},
+			{Path: "$.status", Expected: "one of [available sold]", Got: "lost"},
+			{Path: "$.tags[1]", Expected: "type string", Got: "integer"},
+		}},
+	}
+
+	doc, err := LoadOpenAPI([]byte(petstore))
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	contract, err := doc.ResponseContract("GET", "/pets/{id}", 200)
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	for _, c := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(c.status)
+			fmt.Fprint(w, c.body)
+		}))
+		resp, _ := sling.New().Get(server.URL).Receive(nil, nil)
+		server.Close()
+
+		err := contract.VerifyResponse(resp)
+		var contractErr *ContractError
+		if c.violations == nil {
+			if err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+			continue
+		}
+		if !errors.As(err, &contractErr) {
+			t.Fatalf("expected *ContractError, got %v", err)
+		}
+		if !reflect.DeepEqual(c.violations, contractErr.Violations) {
+			t.Errorf("expected %v, got %v", c.violations, contractErr.Violations)
+		}
+	}
+}
+
+func TestRequestContract(t *testing.T) {
+	doc, err := LoadOpenAPI([]byte(petstore))
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	contract, err := doc.RequestContract("post", "/pets")
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	req, _ := sling.New().Post("http://example.com/pets").BodyJSON(map[string]interface{}{"id": 1}).Request()
+	err = contract.VerifyRequest(req)
+	expected := "contract violation:\n  $.name: expected required field"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+
+	req, _ = sling.New().Put("http://example.com/pets").BodyJSON(map[string]interface{}{"id": 1, "name": "rex"}).Request()
+	err = contract.VerifyRequest(req)
+	if err == nil || !strings.Contains(err.Error(), "method: expected POST, got PUT") {
+		t.Errorf("expected method violation, got %v", err)
+	}
+
+	if _, err := doc.RequestContract("DELETE", "/pets"); err == nil {
+		t.Errorf("expected error for undefined operation")
+	}
+}
+
+func TestResponseContract_mediaTypes(t *testing.T) {
+	doc, err := LoadOpenAPI([]byte(`{"paths": {"/pets": {"get": {"responses": {"200": {"content": {
+		"text/plain": {"schema": {"type": "string"}},
+		"application/xml": {"schema": {"type": "object"}},
+		"application/yaml": {"schema": {"type": "object"}}
+	}}}}}}}`))
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		contract, err := doc.ResponseContract("GET", "/pets", 200)
+		if err != nil || contract.ContentType != "application/xml" {
+			t.Fatalf("expected the first sorted media type, got %q (%v)", contract.ContentType, err)
+		}
+	}
+}
+
+func TestResponseContract_spooled(t *testing.T) {
+	doc, _ := LoadOpenAPI([]byte(petstore))
+	contract, _ := doc.ResponseContract("GET", "/pets/{id}", 200)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id": 1, "tags": ["%s"]}`, strings.Repeat("a", 100))
+	}))
+	defer server.Close()
+	resp, err := sling.New().SpillToDisk(16, t.TempDir()).Get(server.URL).Receive(nil, nil)
+	if err != nil || !resp.Spooled() {
+		t.Fatalf("expected a spooled response, got %v", err)
+	}
+	defer resp.Body.Close()
+	err = contract.VerifyResponse(resp)
+	if err == nil || !strings.Contains(err.Error(), "$.name: expected required field") {
+		t.Errorf("expected the spooled body to be verified, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Package slingtest provides helpers for testing API clients built with sling.
+
+# Contracts
+
+Use a Contract to check recorded requests and responses against the expected
+status, content type and JSON body shape. Contracts can be written by hand or
+extracted from an OpenAPI 3 document.
+
+	doc, err := slingtest.LoadOpenAPI(spec)
+	contract, err := doc.ResponseContract("GET", "/users/{id}", 200)
+
+	resp, err := api.New().Get("users/1").ReceiveSuccess(user)
+	slingtest.AssertResponse(t, resp, contract)
+
+Failed assertions list every violation on its own line, e.g.
+
+	contract violation:
+	  status: expected 200, got 404
+	  $.id: required field
+	  $.age: expected type integer, got string
+
+# Assertions
+
+Assert checks a response with chained assertions, reporting each failure as
+a test error. Golden files are rewritten when SLINGTEST_UPDATE_GOLDEN is set.
+
+	slingtest.Assert(t, resp).
+		StatusIs(200).
+		HeaderEquals("Content-Type", "application/json").
+		JSONPathEquals("items.0.name", "gopher").
+		BodyMatchesGolden("testdata/users.golden.json")
+
+# Server
+
+Use a Server to answer requests with programmed responses instead of writing
+httptest handlers. Routes match on method, path pattern, query, headers and
+JSON body, and every request is recorded.
+
+	srv := slingtest.NewServer(t)
+	users := srv.On("POST", "/users").JSONBody(map[string]string{"name": "gopher"}).
+		ReplyJSON(201, map[string]interface{}{"id": 1})
+	srv.On("GET", "/users/{id}").Header("Accept", "application/json").
+		ReplyJSON(200, map[string]interface{}{"id": 1, "name": "gopher"})
+
+	resp, err := srv.Sling().Post("users").BodyJSON(newUser).ReceiveSuccess(user)
+	fmt.Println(users.Hits(), srv.Hits()[0].Body)
+
+# Credentials
+
+JWT fabricates signed tokens with chosen claims and expiry, and ParseJWT
+verifies them on the server side. Credentials scripts the tokens and
+failures of a credentials provider, for deterministic auth refresh tests.
+
+	creds := new(slingtest.Credentials).
+		ThenToken(slingtest.JWT(slingtest.Claims{"sub": "gopher"}, time.Now().Add(-time.Second))).
+		ThenToken(slingtest.JWT(slingtest.Claims{"sub": "gopher"}, time.Now().Add(time.Hour)))
+*/
+package slingtest
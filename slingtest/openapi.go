@@ -0,0 +1,143 @@
+package slingtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OpenAPI is a parsed OpenAPI 3 document (JSON encoded) from which request and
+// response contracts can be extracted.
+type OpenAPI struct {
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	RequestBody *struct {
+		Content map[string]openAPIMediaType `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]openAPIMediaType `json:"content"`
+	} `json:"responses"`
+}
+
+type openAPIMediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// LoadOpenAPI parses a JSON encoded OpenAPI 3 document.
+func LoadOpenAPI(b []byte) (*OpenAPI, error) {
+	doc := new(OpenAPI)
+	if err := json.Unmarshal(b, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// ResponseContract returns the contract for the response with the given
+// status of the operation at method and path (as written in the document,
+// e.g. "/users/{id}"). The "default" response is used when status is not
+// listed.
+func (o *OpenAPI) ResponseContract(method, path string, status int) (Contract, error) {
+	op, err := o.operation(method, path)
+	if err != nil {
+		return Contract{}, err
+	}
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		resp, ok = op.Responses["default"]
+	}
+	if !ok {
+		return Contract{}, fmt.Errorf("slingtest: no %d response for %s %s", status, method, path)
+	}
+	c := Contract{Status: status}
+	c.ContentType, c.Schema, err = o.mediaType(resp.Content)
+	return c, err
+}
+
+// RequestContract returns the contract for the request body of the operation
+// at method and path.
+func (o *OpenAPI) RequestContract(method, path string) (Contract, error) {
+	op, err := o.operation(method, path)
+	if err != nil {
+		return Contract{}, err
+	}
+	c := Contract{Method: strings.ToUpper(method)}
+	if op.RequestBody != nil {
+		c.ContentType, c.Schema, err = o.mediaType(op.RequestBody.Content)
+	}
+	return c, err
+}
+
+func (o *OpenAPI) operation(method, path string) (openAPIOperation, error) {
+	op, ok := o.Paths[path][strings.ToLower(method)]
+	if !ok {
+		return op, fmt.Errorf("slingtest: no operation %s %s", method, path)
+	}
+	return op, nil
+}
+
+// mediaType picks the JSON media type of content, falling back to the first
+// listed one in sorted order, and returns it with its resolved schema.
+func (o *OpenAPI) mediaType(content map[string]openAPIMediaType) (string, *Schema, error) {
+	if len(content) == 0 {
+		return "", nil, nil
+	}
+	contentType := "application/json"
+	media, ok := content[contentType]
+	if !ok {
+		types := make([]string, 0, len(content))
+		for contentType := range content {
+			types = append(types, contentType)
+		}
+		sort.Strings(types)
+		contentType = types[0]
+		media = content[contentType]
+	}
+	schema, err := o.resolve(media.Schema, map[string]bool{})
+	return contentType, schema, err
+}
+
+// resolve returns a copy of schema with local "#/components/schemas/" refs
+// replaced by their definitions. Recursive definitions are cut off at their
+// first repetition.
+func (o *OpenAPI) resolve(schema *Schema, seen map[string]bool) (*Schema, error) {
+	if schema == nil {
+		return nil, nil
+	}
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		if name == schema.Ref {
+			return nil, fmt.Errorf("slingtest: unsupported $ref %q", schema.Ref)
+		}
+		if seen[name] {
+			return nil, nil
+		}
+		def, ok := o.Components.Schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("slingtest: undefined $ref %q", schema.Ref)
+		}
+		seen[name] = true
+		defer delete(seen, name)
+		return o.resolve(def, seen)
+	}
+	resolved := *schema
+	var err error
+	if resolved.Items, err = o.resolve(schema.Items, seen); err != nil {
+		return nil, err
+	}
+	if schema.Properties != nil {
+		resolved.Properties = make(map[string]*Schema, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			if resolved.Properties[name], err = o.resolve(prop, seen); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &resolved, nil
+}
@@ -0,0 +1,286 @@
+package slingtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/anhdhbn/sling"
+)
+
+// Hit is a request recorded by a Server.
+type Hit struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	Body   []byte
+	// Params holds the values of the {name} segments of the matched route
+	// pattern.
+	Params map[string]string
+	// Route is the matched route, nil for unmatched requests.
+	Route *Route
+}
+
+// Server is an httptest.Server answering requests with programmed responses
+// of the first matching Route. All requests are recorded.
+//
+//	srv := slingtest.NewServer(t)
+//	srv.On("GET", "/users/{id}").Query("expand", "orders").
+//		ReplyJSON(200, map[string]interface{}{"id": 1})
+//
+//	resp, err := srv.Sling().Get("users/1").QueryStruct(params).ReceiveSuccess(user)
+type Server struct {
+	*httptest.Server
+
+	t      testing.TB
+	mu     sync.Mutex
+	routes []*Route
+	hits   []Hit
+}
+
+// NewServer starts a Server which is closed when the test ends. Requests not
+// matching any route are answered with 404 and reported as test errors.
+func NewServer(t testing.TB) *Server {
+	s := &Server{t: t}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Sling returns a new Sling with the server URL as base.
+func (s *Server) Sling() *sling.Sling {
+	return sling.New().Base(s.URL + "/")
+}
+
+// On registers a route matching requests with method (any method if empty)
+// and a path pattern. Pattern segments written as {name} match any single
+// segment and a trailing "*" matches any remaining path.
+func (s *Server) On(method, pattern string) *Route {
+	r := &Route{
+		method:  strings.ToUpper(method),
+		pattern: splitPath(pattern),
+		status:  http.StatusOK,
+		header:  make(http.Header),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes = append(s.routes, r)
+	return r
+}
+
+// Hits returns all recorded requests, matched or not, in arrival order.
+func (s *Server) Hits() []Hit {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Hit(nil), s.hits...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	hit := Hit{Method: req.Method, URL: req.URL, Header: req.Header, Body: body}
+
+	s.mu.Lock()
+	for _, r := range s.routes {
+		if params, ok := r.match(req, body); ok {
+			hit.Params, hit.Route = params, r
+			r.hits.Add(1)
+			break
+		}
+	}
+	s.hits = append(s.hits, hit)
+	s.mu.Unlock()
+
+	if hit.Route == nil {
+		s.t.Errorf("slingtest: unmatched request %s %s", req.Method, req.URL)
+		http.NotFound(w, req)
+		return
+	}
+	hit.Route.write(w)
+}
+
+// Route matches requests and holds the response written for them. Its
+// methods add match conditions or configure the response and return the
+// Route for chaining.
+type Route struct {
+	method  string
+	pattern []string
+	query   url.Values
+	headers http.Header
+	bodies  []func(body []byte) bool
+
+	status int
+	header http.Header
+	body   []byte
+	hits   atomic.Int64
+}
+
+// Query requires the request query to contain key with value.
+func (r *Route) Query(key, value string) *Route {
+	if r.query == nil {
+		r.query = make(url.Values)
+	}
+	r.query.Add(key, value)
+	return r
+}
+
+// Header requires the request header key to contain value.
+func (r *Route) Header(key, value string) *Route {
+	if r.headers == nil {
+		r.headers = make(http.Header)
+	}
+	r.headers.Add(key, value)
+	return r
+}
+
+// JSONBody requires the request body to be JSON equal to v once both are
+// normalized, so key order and whitespace do not matter.
+func (r *Route) JSONBody(v interface{}) *Route {
+	want, err := normalizeJSONValue(v)
+	return r.BodyMatches(func(body []byte) bool {
+		got, gotErr := normalizeJSON(body)
+		return err == nil && gotErr == nil && reflect.DeepEqual(want, got)
+	})
+}
+
+// BodyMatches requires match to report true for the request body.
+func (r *Route) BodyMatches(match func(body []byte) bool) *Route {
+	r.bodies = append(r.bodies, match)
+	return r
+}
+
+// Reply sets the response status code.
+func (r *Route) Reply(status int) *Route {
+	r.status = status
+	return r
+}
+
+// ReplyHeader adds a response header.
+func (r *Route) ReplyHeader(key, value string) *Route {
+	r.header.Add(key, value)
+	return r
+}
+
+// ReplyBody sets the response status and raw body.
+func (r *Route) ReplyBody(status int, body string) *Route {
+	r.status, r.body = status, []byte(body)
+	return r
+}
+
+// ReplyJSON sets the response status and JSON encodes v as the body.
+func (r *Route) ReplyJSON(status int, v interface{}) *Route {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("slingtest: cannot encode reply: %v", err))
+	}
+	r.status, r.body = status, body
+	if r.header.Get("Content-Type") == "" {
+		r.header.Set("Content-Type", "application/json")
+	}
+	return r
+}
+
+// Hits returns the number of requests matched by the route.
+func (r *Route) Hits() int {
+	return int(r.hits.Load())
+}
+
+func (r *Route) match(req *http.Request, body []byte) (map[string]string, bool) {
+	if r.method != "" && r.method != req.Method {
+		return nil, false
+	}
+	params, ok := matchPath(r.pattern, splitPath(req.URL.Path))
+	if !ok {
+		return nil, false
+	}
+	query := req.URL.Query()
+	for key, values := range r.query {
+		for _, v := range values {
+			if !contains(query[key], v) {
+				return nil, false
+			}
+		}
+	}
+	for key, values := range r.headers {
+		for _, v := range values {
+			if !contains(req.Header.Values(key), v) {
+				return nil, false
+			}
+		}
+	}
+	for _, match := range r.bodies {
+		if !match(body) {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func (r *Route) write(w http.ResponseWriter) {
+	for key, values := range r.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(r.status)
+	w.Write(r.body)
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func matchPath(pattern, segments []string) (map[string]string, bool) {
+	params := make(map[string]string)
+	for i, p := range pattern {
+		if p == "*" && i == len(pattern)-1 {
+			return params, true
+		}
+		if i >= len(segments) {
+			return nil, false
+		}
+		if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+			params[p[1:len(p)-1]] = segments[i]
+			continue
+		}
+		if p != segments[i] {
+			return nil, false
+		}
+	}
+	return params, len(pattern) == len(segments)
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeJSON(b []byte) (interface{}, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	err := dec.Decode(&v)
+	return v, err
+}
+
+func normalizeJSONValue(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeJSON(b)
+}
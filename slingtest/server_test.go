@@ -0,0 +1,78 @@
+package slingtest
+
+import (
+	"reflect"
+	"testing"
+)
+
+type fakeUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestServer(t *testing.T) {
+	srv := NewServer(t)
+	create := srv.On("POST", "/users").JSONBody(fakeUser{Name: "gopher"}).
+		ReplyJSON(201, fakeUser{ID: 1, Name: "gopher"})
+	get := srv.On("GET", "/users/{id}").Query("expand", "orders").Header("X-Key", "secret").
+		ReplyHeader("X-Request-Id", "abc").ReplyJSON(200, fakeUser{ID: 2, Name: "other"})
+	srv.On("", "/files/*").ReplyBody(204, "")
+
+	user := new(fakeUser)
+	resp, err := srv.Sling().Post("users").BodyJSON(fakeUser{Name: "gopher"}).ReceiveSuccess(user)
+	if err != nil || resp.StatusCode != 201 {
+		t.Fatalf("expected 201, got %v, %v", resp, err)
+	}
+	if expected := (&fakeUser{ID: 1, Name: "gopher"}); !reflect.DeepEqual(expected, user) {
+		t.Errorf("expected %v, got %v", expected, user)
+	}
+
+	resp, err = srv.Sling().Get("users/2").QueryParams(map[string]string{"expand": "orders"}).
+		SetHeader("X-Key", "secret").ReceiveSuccess(user)
+	if err != nil || resp.StatusCode != 200 || resp.Header.Get("X-Request-Id") != "abc" {
+		t.Fatalf("expected 200 with X-Request-Id, got %v, %v", resp, err)
+	}
+
+	resp, err = srv.Sling().Delete("files/a/b.txt").Receive(nil, nil)
+	if err != nil || resp.StatusCode != 204 {
+		t.Fatalf("expected 204, got %v, %v", resp, err)
+	}
+
+	if create.Hits() != 1 || get.Hits() != 1 {
+		t.Errorf("expected 1 hit per route, got %d and %d", create.Hits(), get.Hits())
+	}
+	hits := srv.Hits()
+	if len(hits) != 3 {
+		t.Fatalf("expected 3 hits, got %d", len(hits))
+	}
+	if hits[1].Params["id"] != "2" || hits[1].Route != get {
+		t.Errorf("expected GET route with id param 2, got %v", hits[1])
+	}
+}
+
+// errorRecorder records test errors instead of failing the test.
+type errorRecorder struct {
+	testing.TB
+	errors int
+}
+
+func (r *errorRecorder) Errorf(format string, args ...interface{}) {
+	r.errors++
+}
+
+func TestServer_unmatched(t *testing.T) {
+	recorder := &errorRecorder{TB: t}
+	srv := NewServer(recorder)
+	srv.On("GET", "/users/{id}").Header("X-Key", "secret").ReplyJSON(200, fakeUser{})
+
+	resp, _ := srv.Sling().Get("users/1").Receive(nil, nil)
+	if resp.StatusCode != 404 {
+		t.Errorf("expected %d, got %d", 404, resp.StatusCode)
+	}
+	if recorder.errors != 1 {
+		t.Errorf("expected unmatched request to fail the test")
+	}
+	if hits := srv.Hits(); len(hits) != 1 || hits[0].Route != nil {
+		t.Errorf("expected 1 unmatched hit, got %v", hits)
+	}
+}
@@ -0,0 +1,71 @@
+package sling
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// spoolFile is a response body spilled to a temporary file. The file is
+// removed when it is closed, or when it becomes unreachable.
+type spoolFile struct {
+	*os.File
+	size int64
+	// threshold is the size above which the body was spooled, see
+	// readOrSpool.
+	threshold int64
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// Close closes and removes the temporary file.
+func (f *spoolFile) Close() error {
+	f.closeOnce.Do(func() {
+		runtime.SetFinalizer(f, nil)
+		f.closeErr = f.File.Close()
+		if err := os.Remove(f.Name()); err != nil && f.closeErr == nil {
+			f.closeErr = err
+		}
+	})
+	return f.closeErr
+}
+
+// readOrSpool reads body into memory if it is at most threshold bytes,
+// otherwise it copies body into a temporary file in dir and returns the file
+// rewound to its start.
+func readOrSpool(body io.Reader, threshold int64, dir string) ([]byte, *spoolFile, error) {
+	buf, err := io.ReadAll(io.LimitReader(body, threshold+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(buf)) <= threshold {
+		return buf, nil, nil
+	}
+
+	f, err := os.CreateTemp(dir, "sling-body-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	spool := &spoolFile{File: f, threshold: threshold}
+	runtime.SetFinalizer(spool, (*spoolFile).Close)
+	spool.size, err = io.Copy(f, io.MultiReader(bytes.NewReader(buf), body))
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		spool.Close()
+		return nil, nil, err
+	}
+	return nil, spool, nil
+}
+
+// nopSeekCloser adds a no-op Close to an io.ReadSeeker.
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopSeekCloser) Close() error {
+	return nil
+}
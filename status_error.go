@@ -0,0 +1,65 @@
+package sling
+
+import (
+	"fmt"
+	"io"
+)
+
+// statusErrorBodyLimit is the size of the body snippet kept by StatusError.
+const statusErrorBodyLimit = 512
+
+// StatusError describes a failure response.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	// Body is the start of the raw response body, at most 512 bytes.
+	Body []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("sling: unexpected status %s: %q", e.Status, e.Body)
+}
+
+// FailureDecodeError is returned when a failure response cannot be decoded
+// into failureV. Both the failure and the decoding error are available
+// through errors.As and errors.Is, e.g.
+//
+//	var statusErr *sling.StatusError
+//	var syntaxErr *json.SyntaxError
+//	if errors.As(err, &statusErr) && errors.As(err, &syntaxErr) {
+//		log.Printf("%d with malformed body %q", statusErr.StatusCode, statusErr.Body)
+//	}
+type FailureDecodeError struct {
+	*StatusError
+	// Err is the decoding error.
+	Err error
+}
+
+func (e *FailureDecodeError) Error() string {
+	return fmt.Sprintf("sling: cannot decode %s response: %v (body %q)", e.Status, e.Err, e.Body)
+}
+
+func (e *FailureDecodeError) Unwrap() []error {
+	return []error{e.StatusError, e.Err}
+}
+
+// newStatusError returns a StatusError for resp with a snippet of its body,
+// redacted if the response has a Redactor. Snippets of spooled bodies can't
+// be redacted, so they are omitted.
+func newStatusError(resp *Response) *StatusError {
+	e := &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	if resp.spool != nil {
+		if resp.redactor != nil {
+			return e
+		}
+		e.Body, _ = io.ReadAll(io.LimitReader(resp.Reader(), statusErrorBodyLimit))
+		resp.ResetBody()
+	} else {
+		e.Body = resp.redactor.Redact(resp.RawData)
+		if len(e.Body) > statusErrorBodyLimit {
+			e.Body = e.Body[:statusErrorBodyLimit]
+		}
+		e.Body = append([]byte(nil), e.Body...)
+	}
+	return e
+}
@@ -0,0 +1,112 @@
+package sling
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// sniffLimit is the size of the body prefix passed to DecodeOnBody matchers.
+const sniffLimit = 512
+
+// And returns a SuccessDecider treating responses as success when all the
+// deciders do, e.g.
+//
+//	sling.And(sling.DecodeOnSuccess, sling.Not(sling.DecodeOnHeader("X-Error", nil)))
+func And(deciders ...SuccessDecider) SuccessDecider {
+	return func(resp *http.Response) bool {
+		for _, decider := range deciders {
+			if !decider(resp) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or returns a SuccessDecider treating responses as success when any of the
+// deciders does.
+func Or(deciders ...SuccessDecider) SuccessDecider {
+	return func(resp *http.Response) bool {
+		for _, decider := range deciders {
+			if decider(resp) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not returns a SuccessDecider treating responses as success when decider
+// does not.
+func Not(decider SuccessDecider) SuccessDecider {
+	return func(resp *http.Response) bool {
+		return !decider(resp)
+	}
+}
+
+// DecodeOnStatusRange returns a SuccessDecider treating status codes from
+// min to max included as success.
+func DecodeOnStatusRange(min, max int) SuccessDecider {
+	return func(resp *http.Response) bool {
+		return min <= resp.StatusCode && resp.StatusCode <= max
+	}
+}
+
+// DecodeOnHeader returns a SuccessDecider treating responses as success when
+// match returns true for the value of their key header. A nil match matches
+// responses with the header present, whatever its value.
+func DecodeOnHeader(key string, match func(value string) bool) SuccessDecider {
+	return func(resp *http.Response) bool {
+		values := resp.Header.Values(key)
+		if match == nil {
+			return len(values) > 0
+		}
+		return match(resp.Header.Get(key))
+	}
+}
+
+// DecodeOnBody returns a SuccessDecider treating responses as success when
+// match returns true for the first 512 bytes of their body, for APIs
+// signalling errors in the body of 200 responses, e.g.
+//
+//	sling.And(sling.DecodeOnSuccess, sling.DecodeOnBody(func(prefix []byte) bool {
+//		return !bytes.HasPrefix(prefix, []byte(`{"error"`))
+//	}))
+//
+// The body is left unread for decoding.
+func DecodeOnBody(match func(prefix []byte) bool) SuccessDecider {
+	return func(resp *http.Response) bool {
+		prefix, err := peekBody(resp, sniffLimit)
+		if err != nil {
+			return false
+		}
+		return match(prefix)
+	}
+}
+
+// peekBody returns up to n bytes of the body of resp, leaving it unread.
+func peekBody(resp *http.Response, n int64) ([]byte, error) {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return nil, nil
+	}
+	prefix, err := io.ReadAll(io.LimitReader(resp.Body, n))
+	if err != nil {
+		return nil, err
+	}
+	switch body := resp.Body.(type) {
+	case io.Seeker:
+		_, err = body.Seek(0, io.SeekStart)
+	case *streamBody:
+		body.ReadCloser = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(prefix), body.ReadCloser), body.ReadCloser}
+	default:
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(prefix), resp.Body), resp.Body}
+	}
+	return prefix, err
+}
@@ -0,0 +1,38 @@
+package sling
+
+import (
+	"io"
+)
+
+// TeeResponse copies the body of responses to w, e.g. to a file or a hash,
+// as it is decoded (after any decryption, see Decrypt). Streamed bodies (see
+// NDJSON) are copied while they are read, in one pass without buffering them,
+// so only the part read by the decoder is copied. Buffered and spooled
+// bodies are copied once received. Use io.MultiWriter to copy bodies to
+// several writers. A nil w disables copying.
+func (s *Sling) TeeResponse(w io.Writer) *Sling {
+	s.checkMutable()
+	s.tee = w
+	return s
+}
+
+// teeBody copies the body of the response to w, see TeeResponse.
+func (r *Response) teeBody(w io.Writer) error {
+	if r.Response == nil {
+		return nil
+	}
+	if stream := r.stream; stream != nil {
+		stream.ReadCloser = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(stream.ReadCloser, w), stream.ReadCloser}
+		return nil
+	}
+	if r.spool != nil {
+		defer r.ResetBody()
+		_, err := io.Copy(w, r.Reader())
+		return err
+	}
+	_, err := w.Write(r.RawData)
+	return err
+}
@@ -0,0 +1,36 @@
+package sling
+
+import (
+	"context"
+)
+
+// TenantKeyFunc derives the tenant of a request from its context, e.g. a
+// customer ID, see TenantKey.
+type TenantKeyFunc func(ctx context.Context) string
+
+type tenantKey struct{}
+
+// TenantKey partitions the throttling (see Throttle) and endpoint health
+// (see TrackEndpointHealth) of requests by the tenant key returns for their
+// context, so that one noisy tenant exhausting a quota doesn't block all
+// tenants sharing the Sling:
+//
+//	s.TenantKey(func(ctx context.Context) string { return customerID(ctx) })
+//
+// Requests with an empty tenant share a default partition. The tenant is
+// available to Middlewares and Doers through TenantFromContext. A nil key
+// disables partitioning. Partitions don't grow without bound: throttling
+// evicts the tenants whose windows ended and endpoint health keeps the most
+// recently observed tenants (see TrackEndpointHealth).
+func (s *Sling) TenantKey(key TenantKeyFunc) *Sling {
+	s.checkMutable()
+	s.tenantKey = key
+	return s
+}
+
+// TenantFromContext returns the tenant of the request sent with ctx, see
+// TenantKey.
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
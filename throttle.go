@@ -0,0 +1,333 @@
+package sling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ThrottleError is returned by a fail fast ThrottleDoer (see
+// WithThrottleFailFast) for requests exceeding its schedule.
+type ThrottleError struct {
+	// Until is when the next request is allowed.
+	Until time.Time
+}
+
+func (e *ThrottleError) Error() string {
+	return fmt.Sprintf("sling: request throttled until %s", e.Until.Format(time.RFC3339))
+}
+
+// ThrottleDoer is a Doer sending requests according to a schedule of
+// per-window quotas and quiet hours. Requests exceeding the schedule wait
+// until they are allowed (or their context is done), or fail with a
+// *ThrottleError, wrapped in a CancellationError, when WithThrottleFailFast
+// is set.
+type ThrottleDoer struct {
+	Doer Doer
+
+	state *throttleState
+}
+
+var _ Doer = &ThrottleDoer{}
+
+// ThrottleOption configures a ThrottleDoer.
+type ThrottleOption func(state *throttleState)
+
+// WithWindowLimit allows at most max requests per window, e.g. 1000 per
+// time.Hour. Windows are aligned to multiples of window since the zero
+// time, so hourly windows start on the hour and daily windows at midnight
+// UTC.
+func WithWindowLimit(max int, window time.Duration) ThrottleOption {
+	return func(state *throttleState) {
+		if max > 0 && window > 0 {
+			state.limits = append(state.limits, &windowLimit{Max: max, Window: window})
+		}
+	}
+}
+
+// WithQuietHours holds requests between the start and end offsets from
+// midnight in loc (time.Local if nil), e.g. 22*time.Hour and 6*time.Hour.
+// Ranges may wrap around midnight.
+func WithQuietHours(start, end time.Duration, loc *time.Location) ThrottleOption {
+	return func(state *throttleState) {
+		if loc == nil {
+			loc = time.Local
+		}
+		state.quiet = append(state.quiet, quietHours{start: start, end: end, loc: loc})
+	}
+}
+
+// WithThrottleState persists the window counts to the file at path, so
+// quotas survive restarts. Counts are loaded when the ThrottleDoer is
+// created and saved after each request, on a best effort basis.
+func WithThrottleState(path string) ThrottleOption {
+	return func(state *throttleState) {
+		state.path = path
+	}
+}
+
+// WithThrottleFailFast makes requests exceeding the schedule fail with a
+// *ThrottleError instead of waiting.
+func WithThrottleFailFast() ThrottleOption {
+	return func(state *throttleState) {
+		state.failFast = true
+	}
+}
+
+// NewThrottleDoer returns a ThrottleDoer sending requests with doer (the
+// default client if nil) according to the schedule of opts.
+func NewThrottleDoer(doer Doer, opts ...ThrottleOption) *ThrottleDoer {
+	if doer == nil {
+		doer = defaultClient
+	}
+	state := &throttleState{now: time.Now}
+	for _, opt := range opts {
+		opt(state)
+	}
+	state.load()
+	return &ThrottleDoer{Doer: doer, state: state}
+}
+
+// Throttle sends requests according to a schedule of window quotas and
+// quiet hours, see NewThrottleDoer. Throttling after AutoRetry counts each
+// request once, throttling before it counts every attempt.
+func (s *Sling) Throttle(opts ...ThrottleOption) *Sling {
+	s.checkMutable()
+	s.httpClient = NewThrottleDoer(s.httpClient, opts...)
+	return s
+}
+
+// Do waits until the schedule allows req, then sends it. Requests of each
+// tenant (see TenantKey) are counted separately.
+func (d *ThrottleDoer) Do(req *http.Request) (*http.Response, []byte, error) {
+	state := d.state.forTenant(TenantFromContext(req.Context()))
+	if err := state.wait(req.Context()); err != nil {
+		return nil, nil, err
+	}
+	return d.Doer.Do(req)
+}
+
+// throttleState is the schedule of a ThrottleDoer, shared by its copies.
+type throttleState struct {
+	limits   []*windowLimit
+	quiet    []quietHours
+	path     string
+	failFast bool
+	now      func() time.Time
+
+	mu sync.Mutex
+	// schedules of tenants, see TenantKey
+	tenants map[string]*throttleState
+	// number of tenants from which idle ones are evicted, see forTenant
+	sweepAt int
+	// version of the counts, and the last version written to path
+	version uint64
+	saveMu  sync.Mutex
+	saved   uint64
+}
+
+// minTenantSweep is the number of tenant schedules from which idle ones are
+// first evicted.
+const minTenantSweep = 64
+
+// forTenant returns the schedule of tenant, with the limits and quiet hours
+// of s but its own counts, which are not persisted. The empty tenant uses s.
+// Schedules of tenants whose windows all ended, which would start counting
+// from zero again, are evicted as new tenants are added, so that the
+// tenants kept are the ones with requests in their current windows.
+func (s *throttleState) forTenant(tenant string) *throttleState {
+	if tenant == "" {
+		return s
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state, ok := s.tenants[tenant]; ok {
+		return state
+	}
+	now := s.now()
+	if len(s.tenants) >= s.sweepAt {
+		for key, state := range s.tenants {
+			if state.idle(now) {
+				delete(s.tenants, key)
+			}
+		}
+		s.sweepAt = max(2*len(s.tenants), minTenantSweep)
+	}
+	state := &throttleState{quiet: s.quiet, failFast: s.failFast, now: s.now}
+	for _, l := range s.limits {
+		// the current windows keep the new schedule from being evicted
+		// before its first request
+		state.limits = append(state.limits, &windowLimit{Max: l.Max, Window: l.Window, Start: now.Truncate(l.Window)})
+	}
+	if s.tenants == nil {
+		s.tenants = make(map[string]*throttleState)
+	}
+	s.tenants[tenant] = state
+	return state
+}
+
+// idle reports whether the windows of all the limits of s ended at now, so
+// that s would count as a new schedule.
+func (s *throttleState) idle(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, l := range s.limits {
+		if now.Truncate(l.Window).Equal(l.Start) {
+			return false
+		}
+	}
+	return true
+}
+
+// windowLimit is a quota and the request count of its current window.
+type windowLimit struct {
+	Max    int           `json:"max"`
+	Window time.Duration `json:"window"`
+	Start  time.Time     `json:"start"`
+	Count  int           `json:"count"`
+}
+
+type quietHours struct {
+	start, end time.Duration
+	loc        *time.Location
+}
+
+// until returns the end of the quiet hours containing now, reporting false
+// outside quiet hours.
+func (q quietHours) until(now time.Time) (time.Time, bool) {
+	t := now.In(q.loc)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, q.loc)
+	offset := t.Sub(midnight)
+	switch {
+	case q.start <= q.end:
+		if offset >= q.start && offset < q.end {
+			return midnight.Add(q.end), true
+		}
+	case offset >= q.start:
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, q.loc).Add(q.end), true
+	case offset < q.end:
+		return midnight.Add(q.end), true
+	}
+	return time.Time{}, false
+}
+
+// wait blocks until a request is allowed and counts it.
+func (s *throttleState) wait(ctx context.Context) error {
+	for {
+		until := s.reserve()
+		if until.IsZero() {
+			return nil
+		}
+		AddSpanEvent(ctx, SpanEventThrottled, attribute.String("sling.throttle.until", until.Format(time.RFC3339)))
+		if s.failFast {
+			return &CancellationError{Reason: CancelThrottled, Err: &ThrottleError{Until: until}}
+		}
+		timer := time.NewTimer(until.Sub(s.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve counts a request if the schedule allows it now, otherwise it
+// returns when to try again. The counts are saved once unlocked.
+func (s *throttleState) reserve() time.Time {
+	until, counts, version := s.count()
+	if counts != nil {
+		s.save(counts, version)
+	}
+	return until
+}
+
+// count counts a request like reserve, returning a snapshot of the counts to
+// save, if any.
+func (s *throttleState) count() (time.Time, []byte, uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	var until time.Time
+	for _, q := range s.quiet {
+		if end, ok := q.until(now); ok && end.After(until) {
+			until = end
+		}
+	}
+	for _, l := range s.limits {
+		start := now.Truncate(l.Window)
+		if !start.Equal(l.Start) {
+			l.Start, l.Count = start, 0
+		}
+		if end := start.Add(l.Window); l.Count >= l.Max && end.After(until) {
+			until = end
+		}
+	}
+	if !until.IsZero() {
+		return until, nil, 0
+	}
+	for _, l := range s.limits {
+		l.Count++
+	}
+	if s.path == "" {
+		return time.Time{}, nil, 0
+	}
+	counts, err := json.Marshal(s.limits)
+	if err != nil {
+		return time.Time{}, nil, 0
+	}
+	s.version++
+	return time.Time{}, counts, s.version
+}
+
+// load restores the counts of limits with the same quota from the state
+// file.
+func (s *throttleState) load() {
+	if s.path == "" {
+		return
+	}
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return
+	}
+	var saved []windowLimit
+	if json.Unmarshal(b, &saved) != nil {
+		return
+	}
+	for _, l := range s.limits {
+		for _, prev := range saved {
+			if prev.Max == l.Max && prev.Window == l.Window {
+				l.Start, l.Count = prev.Start, prev.Count
+			}
+		}
+	}
+}
+
+// save writes the snapshot counts of version to the state file, replacing
+// it atomically, unless later counts were already written.
+func (s *throttleState) save(counts []byte, version uint64) {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	if version <= s.saved {
+		return
+	}
+	s.saved = version
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(counts)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || os.Rename(tmp.Name(), s.path) != nil {
+		os.Remove(tmp.Name())
+	}
+}
@@ -0,0 +1,103 @@
+package sling
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	goquery "github.com/google/go-querystring/query"
+)
+
+// timeFormat renders time.Time values of query structs and form bodies, see
+// TimeFormat.
+type timeFormat struct {
+	layout string
+	loc    *time.Location
+}
+
+// TimeFormat renders the time.Time (and *time.Time) fields of query structs
+// (see QueryStruct) and form bodies (see BodyForm) in loc with layout,
+// rather than with RFC3339 in their own location, for legacy APIs which
+// expect local time parameters:
+//
+//	berlin, _ := time.LoadLocation("Europe/Berlin")
+//	s.TimeFormat("2006-01-02 15:04:05", berlin)
+//
+// An empty layout means time.RFC3339 and a nil loc keeps the location of
+// each time. Fields with their own layout tag or unix option are left as
+// is. Only top level and embedded struct fields are rendered.
+func (s *Sling) TimeFormat(layout string, loc *time.Location) *Sling {
+	s.checkMutable()
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	s.timeFormat = &timeFormat{layout: layout, loc: loc}
+	return s
+}
+
+// encodeValues encodes the url tagged struct v with go-querystring, time
+// fields being rendered with f if not nil.
+func encodeValues(v interface{}, f *timeFormat) (url.Values, error) {
+	values, err := goquery.Values(v)
+	if err != nil || f == nil {
+		return values, err
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return values, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Struct {
+		f.render(rv, values)
+	}
+	return values, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// render sets the values of the time fields of the struct rv.
+func (f *timeFormat) render(rv reflect.Value, values url.Values) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("url")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := rv.Field(i)
+		for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Ptr || fv.Kind() != reflect.Struct {
+			continue
+		}
+		if fv.Type() != timeType {
+			if field.Anonymous && name == "" {
+				f.render(fv, values)
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if field.Tag.Get("layout") != "" || strings.Contains(opts, "unix") {
+			continue
+		}
+		if _, ok := values[name]; !ok {
+			// omitted, e.g. omitempty zero times
+			continue
+		}
+		t := fv.Interface().(time.Time)
+		if f.loc != nil {
+			t = t.In(f.loc)
+		}
+		values.Set(name, t.Format(f.layout))
+	}
+}
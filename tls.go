@@ -0,0 +1,108 @@
+package sling
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// TLSSessionCache resumes TLS sessions with the servers the Sling connects
+// to, keeping up to size sessions (64 if size <= 0) in an LRU cache keyed by
+// server name. Resumed handshakes skip certificate exchange, which cuts the
+// latency of clients making many short connections. The cache is set on
+// the Sling's own copy of the transport (see Doer).
+//
+// TLS 1.3 early data (0-RTT) is not offered: crypto/tls does not support it
+// for clients.
+func (s *Sling) TLSSessionCache(size int) *Sling {
+	s.checkMutable()
+	s.configureTransport("TLSSessionCache", strconv.Itoa(size), func(t *http.Transport) {
+		config := tlsClientConfig(t)
+		config.SessionTicketsDisabled = false
+		config.ClientSessionCache = tls.NewLRUClientSessionCache(size)
+	})
+	return s
+}
+
+// DisableTLSSessionTickets disables TLS session resumption, so every
+// connection performs a full handshake, e.g. for servers mishandling
+// tickets. It replaces any cache set by TLSSessionCache.
+func (s *Sling) DisableTLSSessionTickets() *Sling {
+	s.checkMutable()
+	s.configureTransport("DisableTLSSessionTickets", "", func(t *http.Transport) {
+		config := tlsClientConfig(t)
+		config.SessionTicketsDisabled = true
+		config.ClientSessionCache = nil
+	})
+	return s
+}
+
+// ClientTLS authenticates the Sling to servers requiring mutual TLS with
+// the PEM encoded certificate and key of certFile and keyFile, and verifies
+// servers against the PEM encoded CA certificates of caFile, or the system
+// roots if caFile is empty:
+//
+//	s := sling.New().ClientTLS("client.crt", "client.key", "ca.crt")
+//
+// The certificate is added to a clone of the transport's TLS config (see
+// Doer), keeping settings such as a TLSSessionCache. Files which cannot be
+// loaded are recorded as a builder error (see Err).
+func (s *Sling) ClientTLS(certFile, keyFile, caFile string) *Sling {
+	s.checkMutable()
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		s.addErr("ClientTLS", certFile, err)
+		return s
+	}
+	var roots *x509.CertPool
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			s.addErr("ClientTLS", caFile, err)
+			return s
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			s.addErr("ClientTLS", caFile, errors.New("no PEM encoded certificates"))
+			return s
+		}
+	}
+	s.configureTransport("ClientTLS", certFile, func(t *http.Transport) {
+		config := tlsClientConfig(t)
+		config.Certificates = []tls.Certificate{cert}
+		if roots != nil {
+			config.RootCAs = roots
+		}
+	})
+	return s
+}
+
+// TLSConfig sets the TLS configuration of the Sling's connections to a clone
+// of config, e.g. for mutual TLS with certificates from a secret store, so
+// callers don't construct their own http.Client. Later changes to config
+// don't affect the Sling. It replaces the TLS settings of previous calls,
+// so call TLSSessionCache or ClientTLS after it to combine them.
+func (s *Sling) TLSConfig(config *tls.Config) *Sling {
+	s.checkMutable()
+	if config == nil {
+		return s
+	}
+	s.configureTransport("TLSConfig", "*tls.Config", func(t *http.Transport) {
+		t.TLSClientConfig = config.Clone()
+	})
+	return s
+}
+
+// tlsClientConfig replaces the TLS config of t by a clone which can be
+// modified without affecting other transports, and returns it.
+func tlsClientConfig(t *http.Transport) *tls.Config {
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	} else {
+		t.TLSClientConfig = t.TLSClientConfig.Clone()
+	}
+	return t.TLSClientConfig
+}
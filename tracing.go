@@ -0,0 +1,56 @@
+package sling
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span events added by sling's subsystems onto the active span, see
+// AddSpanEvent.
+const (
+	// SpanEventRetry is added before each retry of a RetryDoer.
+	SpanEventRetry = "retry"
+	// SpanEventCacheHit is added for requests answered by Memoize.
+	SpanEventCacheHit = "cache-hit"
+	// SpanEventThrottled is added when a ThrottleDoer holds a request.
+	SpanEventThrottled = "throttled"
+	// SpanEventQueued is added when an Outbox queues a request.
+	SpanEventQueued = "queued"
+)
+
+// SpanAttributes sets attrs on the span active in the context of each request
+// sent with the Sling, so traces carry application level details such as
+// the tenant or the operation:
+//
+//	s.SpanAttributes(attribute.String("tenant", id), attribute.String("api.operation", "ListUsers"))
+//
+// Attributes are appended to those of the parent Sling.
+func (s *Sling) SpanAttributes(attrs ...attribute.KeyValue) *Sling {
+	s.checkMutable()
+	s.spanAttributes = append(s.spanAttributes, attrs...)
+	return s
+}
+
+// AddSpanEvent adds the event name with attrs to the span active in ctx, if
+// it is recording. sling uses it to record its own decisions (retries, cache
+// hits, throttling...) and middlewares can use it to record theirs.
+func AddSpanEvent(ctx context.Context, name string, attrs ...attribute.KeyValue) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	span.AddEvent(name, trace.WithAttributes(attrs...))
+}
+
+// setSpanAttributes sets the Sling's attributes on the span active in ctx.
+func (s *Sling) setSpanAttributes(ctx context.Context) {
+	if len(s.spanAttributes) == 0 {
+		return
+	}
+	span := trace.SpanFromContext(ctx)
+	if span.IsRecording() {
+		span.SetAttributes(s.spanAttributes...)
+	}
+}
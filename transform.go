@@ -0,0 +1,64 @@
+package sling
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// ResponseTransformer transforms a successfully decoded response value into
+// another value, e.g. unwrapping a {"data": ...} envelope or converting a
+// transport DTO into a domain type.
+type ResponseTransformer func(v interface{}) (interface{}, error)
+
+// transformResponse decodes rawData into a generic value, runs it through the
+// transformers in order and stores the final value into the value pointed to
+// by successV. The protocol buffer decoders, which need a proto.Message,
+// can't decode generic values and fail.
+func transformResponse(rawData []byte, decoder ResponseDecoder, transformers []ResponseTransformer, successV interface{}) error {
+	switch decoder.(type) {
+	case JsonpbDecoder, *JsonpbDecoder, ProtoDecoder, *ProtoDecoder:
+		return fmt.Errorf("sling: cannot transform responses decoded with %T, which decodes proto.Message values only", decoder)
+	}
+	var v interface{}
+	if err := decoder.Decode(rawData, &v); err != nil {
+		return err
+	}
+	for _, transform := range transformers {
+		var err error
+		v, err = transform(v)
+		if err != nil {
+			return err
+		}
+	}
+	return assignValue(successV, v)
+}
+
+// assignValue stores v into the value pointed to by dst. Values assignable to
+// the pointed to type are set directly, anything else (e.g. a generic
+// map[string]interface{}) is converted with a JSON round trip.
+func assignValue(dst interface{}, v interface{}) error {
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Pointer || dstValue.IsNil() {
+		return fmt.Errorf("sling: cannot assign transformed response to non-pointer %T", dst)
+	}
+	elem := dstValue.Elem()
+	if v == nil {
+		elem.Set(reflect.Zero(elem.Type()))
+		return nil
+	}
+	srcValue := reflect.ValueOf(v)
+	if srcValue.Type().AssignableTo(elem.Type()) {
+		elem.Set(srcValue)
+		return nil
+	}
+	if srcValue.Kind() == reflect.Pointer && !srcValue.IsNil() && srcValue.Elem().Type().AssignableTo(elem.Type()) {
+		elem.Set(srcValue.Elem())
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
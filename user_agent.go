@@ -0,0 +1,44 @@
+package sling
+
+import (
+	"net/http"
+	"strings"
+)
+
+const hdrUserAgentKey = "User-Agent"
+
+// DefaultUserAgent is the User-Agent of requests which don't set one, e.g.
+// "sling/1.5.0", since many APIs reject requests without it.
+var DefaultUserAgent = "sling/" + version
+
+// UserAgent sets the User-Agent header of new requests, replacing the
+// DefaultUserAgent.
+func (s *Sling) UserAgent(userAgent string) *Sling {
+	return s.SetHeader(hdrUserAgentKey, userAgent)
+}
+
+// AppendUserAgent appends the "product/version" component to the
+// User-Agent of new requests, or of the DefaultUserAgent if none is set:
+//
+//	s.AppendUserAgent("billing-service", "2.3.1") // sling/1.5.0 billing-service/2.3.1
+//
+// An empty version appends the product alone.
+func (s *Sling) AppendUserAgent(product, version string) *Sling {
+	component := product
+	if version != "" {
+		component += "/" + version
+	}
+	userAgent := s.header.Get(hdrUserAgentKey)
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	return s.UserAgent(strings.TrimSpace(userAgent + " " + component))
+}
+
+// setDefaultUserAgent sets the DefaultUserAgent on req if it has no
+// User-Agent.
+func setDefaultUserAgent(req *http.Request) {
+	if _, ok := req.Header[hdrUserAgentKey]; !ok && DefaultUserAgent != "" {
+		req.Header.Set(hdrUserAgentKey, DefaultUserAgent)
+	}
+}
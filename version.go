@@ -0,0 +1,88 @@
+package sling
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+
+	otelhttp "go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// version is the semantic version of this release of sling.
+const version = "1.5.0"
+
+// modulePath is the path of the sling module, see BuildInfo.
+const modulePath = "github.com/anhdhbn/sling"
+
+// Version returns the semantic version of sling, e.g. "1.5.0".
+func Version() string {
+	return version
+}
+
+// BuildInfo describes the sling build and client configuration of a Sling,
+// useful when several services report client issues.
+type BuildInfo struct {
+	// Version is the sling version, see Version.
+	Version string
+	// ModuleVersion is the version of the sling module the binary was built
+	// with, "(devel)" for local builds and empty if unknown.
+	ModuleVersion string
+	// GoVersion is the Go version the binary was built with.
+	GoVersion string
+	// OtelEnabled reports whether requests are sent through an
+	// OpenTelemetry instrumented transport.
+	OtelEnabled bool
+	// Transport is the type of the http.RoundTripper sending requests, or of
+	// the Doer if it is not an HttpWrapper.
+	Transport string
+}
+
+// BuildInfo returns the build info of sling and of the Sling's client.
+func (s *Sling) BuildInfo() BuildInfo {
+	info := BuildInfo{Version: version, GoVersion: runtime.Version()}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if build.Main.Path == modulePath {
+			info.ModuleVersion = build.Main.Version
+		}
+		for _, dep := range build.Deps {
+			if dep.Path == modulePath {
+				info.ModuleVersion = dep.Version
+			}
+		}
+	}
+	doer := unwrapDoer(s.httpClient)
+	wrapper, ok := doer.(*HttpWrapper)
+	if !ok {
+		info.Transport = fmt.Sprintf("%T", doer)
+		return info
+	}
+	transport := wrapper.http.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	_, info.OtelEnabled = transport.(*otelhttp.Transport)
+	if wrapper.transport != nil {
+		info.Transport = fmt.Sprintf("%T", wrapper.transport)
+	} else {
+		info.Transport = fmt.Sprintf("%T", transport)
+	}
+	return info
+}
+
+// unwrapDoer returns the Doer at the bottom of the known wrapping Doers.
+func unwrapDoer(doer Doer) Doer {
+	for {
+		if doer == nil {
+			return defaultClient
+		}
+		switch d := doer.(type) {
+		case *RetryDoer:
+			doer = d.HTTPClient
+		case *ThrottleDoer:
+			doer = d.Doer
+		default:
+			return doer
+		}
+	}
+}